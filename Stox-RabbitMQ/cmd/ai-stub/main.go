@@ -0,0 +1,42 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"stox-rabbitmq/internal/ai"
+)
+
+// ai-stub serves the deterministic mock provider over the HTTP provider protocol,
+// standing in for a real inference server when testing AI_PROVIDERS=http.
+func main() {
+	addr := flag.String("addr", ":8090", "listen address")
+	latency := flag.Duration("latency", 0, "delay added to every call (exercises AI_TIMEOUT)")
+	failRate := flag.Float64("fail-rate", 0, "fraction of calls answered with HTTP 500 (exercises fallback)")
+	flag.Parse()
+
+	log.Println("🧪 Starting Stox AI stand-in server...")
+
+	handler := ai.Handler(ai.NewMock(ai.Pricing{}))
+
+	http.Handle("/", http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if *latency > 0 {
+			select {
+			case <-time.After(*latency):
+			case <-r.Context().Done():
+				return
+			}
+		}
+		if rand.Float64() < *failRate {
+			http.Error(w, `{"error":"injected failure"}`, http.StatusInternalServerError)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+
+	log.Printf("✅ Listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, nil))
+}
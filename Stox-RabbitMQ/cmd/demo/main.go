@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"stox-rabbitmq/internal/config"
+	"stox-rabbitmq/internal/imaging"
 	"stox-rabbitmq/internal/models"
 	"stox-rabbitmq/internal/rabbitmq"
 )
@@ -23,7 +24,16 @@ func main() {
 
 	// Create RabbitMQ client
 	client, err := rabbitmq.NewClient(rabbitmq.Config{
-		URL: cfg.GetRabbitMQURL(),
+		URL:             cfg.GetRabbitMQURL(),
+		ValidateSchemas: cfg.ValidateSchemas,
+
+		Compression:          cfg.Compression,
+		CompressionThreshold: cfg.CompressionThreshold,
+		MaxDecompressedSize:  cfg.MaxDecompressedSize,
+
+		ClaimCheckDir:       cfg.ClaimCheckDir,
+		ClaimCheckThreshold: cfg.ClaimCheckThreshold,
+		ClaimCheckTTL:       cfg.ClaimCheckTTL,
 	})
 	if err != nil {
 		log.Fatalf("Failed to create RabbitMQ client: %v", err)
@@ -40,7 +50,7 @@ func main() {
 	log.Println()
 	log.Println("🚀 This demo will show the complete Stox platform workflow:")
 	log.Println("   1. Image Upload → AI Enhancement")
-	log.Println("   2. SEO Content Generation")  
+	log.Println("   2. SEO Content Generation")
 	log.Println("   3. Multi-Marketplace Broadcasting")
 	log.Println("   4. Order Processing & Inventory Sync")
 	log.Println()
@@ -76,6 +86,7 @@ func runDemo(client *rabbitmq.Client) {
 			Price:       149.99,
 			Currency:    "USD",
 			Category:    "Electronics",
+			Attributes:  map[string]string{"brand": "Stox Audio", "color": "Black", "warranty": "2 years"},
 			Status:      "uploaded",
 			Images: []models.Image{
 				{
@@ -87,7 +98,7 @@ func runDemo(client *rabbitmq.Client) {
 					Format:      "JPEG",
 				},
 				{
-					ID:          "demo_img_002", 
+					ID:          "demo_img_002",
 					OriginalURL: "https://example.com/earbuds2.jpg",
 					Size:        980000,
 					Width:       1800,
@@ -106,6 +117,7 @@ func runDemo(client *rabbitmq.Client) {
 			Price:       89.99,
 			Currency:    "USD",
 			Category:    "Electronics",
+			Attributes:  map[string]string{"brand": "Stox Home"}, // no color or warranty: Trendyol and Hepsiburada send it to review
 			Status:      "uploaded",
 			Images: []models.Image{
 				{
@@ -132,14 +144,24 @@ func runDemo(client *rabbitmq.Client) {
 		log.Printf("   Product ID: %s", product.ID)
 		log.Printf("   Images: %d", len(product.Images))
 		log.Printf("   Category: %s", product.Category)
-		
+
+		// Attach generated images so the pipeline runs without fetching example URLs
+		for j := range product.Images {
+			data, err := imaging.PlaceholderJPEG(product.Images[j].Width, product.Images[j].Height)
+			if err != nil {
+				log.Printf("❌ Failed to render image for %s: %v", product.ID, err)
+				continue
+			}
+			product.Images[j].Data = data
+		}
+
 		// Send to image processing pipeline
 		err := client.PublishMessage("", "image_uploads", product)
 		if err != nil {
 			log.Printf("❌ Failed to upload product %s: %v", product.ID, err)
 			continue
 		}
-		
+
 		log.Printf("✅ Product %s sent to image processing pipeline", product.ID)
 		log.Println()
 	}
@@ -266,7 +288,7 @@ func runDemo(client *rabbitmq.Client) {
 
 		// Route order to appropriate marketplace service
 		routingKey := fmt.Sprintf("order.%s.%s", order.Marketplace, getRegionCode(order.CustomerInfo.Address.Country))
-		
+
 		err := client.PublishMessage("stox.orders", routingKey, order)
 		if err != nil {
 			log.Printf("❌ Failed to route order: %v", err)
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"stox-rabbitmq/internal/config"
+	"stox-rabbitmq/internal/eventstore"
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/rabbitmq"
+)
+
+func main() {
+	query := flag.Bool("query", false, "print archived events matching the filter and exit")
+	replay := flag.Bool("replay", false, "republish archived events matching the filter and exit")
+	productID := flag.String("product", "", "filter by product ID")
+	eventType := flag.String("type", "", "filter by event type (image_uploaded, ai_enhanced, ...)")
+	from := flag.String("from", "", "filter events at or after this RFC3339 time")
+	to := flag.String("to", "", "filter events before this RFC3339 time")
+	exchange := flag.String("exchange", "", "replay target exchange (default: original exchange)")
+	flag.Parse()
+
+	log.Println("🗄️  Starting Stox Event Archiver...")
+
+	// Load configuration
+	cfg := config.LoadConfig()
+	cfg.ServiceName = "event-archiver"
+
+	store, err := eventstore.Open(cfg.EventStoreDir)
+	if err != nil {
+		log.Fatalf("Failed to open event store: %v", err)
+	}
+
+	filter, err := buildFilter(*productID, *eventType, *from, *to)
+	if err != nil {
+		log.Fatalf("Invalid filter: %v", err)
+	}
+
+	if *query {
+		if err := printEvents(store, filter); err != nil {
+			log.Fatalf("Query failed: %v", err)
+		}
+		return
+	}
+
+	// Create RabbitMQ client
+	client, err := rabbitmq.NewClient(rabbitmq.Config{
+		URL:             cfg.GetRabbitMQURL(),
+		ValidateSchemas: cfg.ValidateSchemas,
+
+		Compression:          cfg.Compression,
+		CompressionThreshold: cfg.CompressionThreshold,
+		MaxDecompressedSize:  cfg.MaxDecompressedSize,
+
+		ClaimCheckDir:       cfg.ClaimCheckDir,
+		ClaimCheckThreshold: cfg.ClaimCheckThreshold,
+		ClaimCheckTTL:       cfg.ClaimCheckTTL,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create RabbitMQ client: %v", err)
+	}
+	defer client.Close()
+
+	// Setup exchanges
+	err = client.SetupExchanges()
+	if err != nil {
+		log.Fatalf("Failed to setup exchanges: %v", err)
+	}
+
+	if *replay {
+		if _, err := store.Replay(context.Background(), client, filter, *exchange); err != nil {
+			log.Fatalf("Replay failed: %v", err)
+		}
+		return
+	}
+
+	// One archive queue bound to every place events are published
+	bindings := []struct {
+		exchange string
+		routing  string
+	}{
+		{"stox.images", "event.#"},        // Topic - image_uploaded, ai_enhanced, seo_generated
+		{"stox.images", "image.rejected"}, // Topic - image_rejected
+		{"stox.listings", ""},             // Fanout - marketplace_listed (and products, filtered below)
+	}
+
+	for _, b := range bindings {
+		err = client.DeclareQueue("event_archive", b.exchange, b.routing)
+		if err != nil {
+			log.Fatalf("Failed to declare archive queue on %s: %v", b.exchange, err)
+		}
+	}
+
+	log.Printf("✅ Event Archiver initialized (store: %s)", cfg.EventStoreDir)
+
+	go func() {
+		err := client.ConsumeDeliveries("event_archive", func(d rabbitmq.Delivery) error {
+			return archiveEvent(store, d)
+		})
+		if err != nil {
+			log.Printf("Event archive consumer error: %v", err)
+		}
+	}()
+
+	// Wait for interrupt signal
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+
+	log.Println("🗄️  Event Archiver shutting down...")
+}
+
+// archiveEvent appends a ProcessingEvent delivery to the store
+func archiveEvent(store *eventstore.Store, d rabbitmq.Delivery) error {
+	// Replayed events were archived when they were first published
+	if eventstore.Replayed(d.Headers) {
+		return nil
+	}
+
+	var event models.ProcessingEvent
+	err := json.Unmarshal(d.Body, &event)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	// The listings fanout also carries products; those have no event type
+	if event.Type == "" {
+		return nil
+	}
+
+	err = store.Append(eventstore.Record{
+		Exchange:   d.Exchange,
+		RoutingKey: d.RoutingKey,
+		Event:      event,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to archive event %s: %w", event.ID, err)
+	}
+
+	log.Printf("🗄️  Archived %s for product %s", event.Type, event.ProductID)
+	return nil
+}
+
+// printEvents writes matching records to stdout as JSON lines
+func printEvents(store *eventstore.Store, filter eventstore.Filter) error {
+	records, err := store.Query(filter)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	for _, r := range records {
+		if err := encoder.Encode(r); err != nil {
+			return err
+		}
+	}
+
+	log.Printf("🔍 %d event(s) matched", len(records))
+	return nil
+}
+
+// buildFilter parses command line filter flags
+func buildFilter(productID, eventType, from, to string) (eventstore.Filter, error) {
+	filter := eventstore.Filter{
+		ProductID: productID,
+		Type:      eventType,
+	}
+
+	var err error
+	if from != "" {
+		if filter.From, err = time.Parse(time.RFC3339, from); err != nil {
+			return filter, fmt.Errorf("invalid -from: %w", err)
+		}
+	}
+	if to != "" {
+		if filter.To, err = time.Parse(time.RFC3339, to); err != nil {
+			return filter, fmt.Errorf("invalid -to: %w", err)
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"stox-rabbitmq/internal/marketplace"
+)
+
+// mock-marketplace emulates the Amazon, Trendyol and Hepsiburada seller APIs
+// (listings, stock, prices and orders) so marketplace services can be run and
+// tested without marketplace access. Point <MARKETPLACE>_API_URL at
+// http://<addr>/<marketplace>; recorded calls are at GET /_calls.
+func main() {
+	addr := flag.String("addr", ":9090", "listen address")
+	profilesFile := flag.String("profiles", "", "JSON file of per-marketplace profiles (defaults otherwise)")
+	latency := flag.Duration("latency", 0, "latency added to every call, for all marketplaces")
+	errorRate := flag.Float64("error-rate", 0, "fraction of calls answered with HTTP 500, for all marketplaces")
+	rejectRate := flag.Float64("reject-rate", 0, "fraction of listings rejected with HTTP 422, for all marketplaces")
+	rateLimit := flag.Float64("rate-limit", 0, "requests per second before HTTP 429, for all marketplaces (0 for unlimited)")
+	orderInterval := flag.Duration("order-interval", 30*time.Second, "how often to place an order for a listed SKU (0 disables)")
+	secret := flag.String("webhook-secret", getEnv("MOCK_WEBHOOK_SECRET", "stox-dev-webhook-secret"), "secret order webhooks are signed with")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "random seed for injected failures and generated IDs")
+	webhooks := make(map[string]string)
+	flag.Func("webhook", "marketplace=url to deliver new orders to (repeatable)", func(s string) error {
+		mp, url, ok := strings.Cut(s, "=")
+		if !ok {
+			return fmt.Errorf("expected marketplace=url")
+		}
+		webhooks[mp] = url
+		return nil
+	})
+	flag.Parse()
+
+	log.Println("🧪 Starting Stox mock marketplace server...")
+
+	profiles := make(map[string]marketplace.Profile)
+	for mp, profile := range marketplace.DefaultProfiles {
+		profiles[mp] = profile
+	}
+	if *profilesFile != "" {
+		data, err := os.ReadFile(*profilesFile)
+		if err != nil {
+			log.Fatalf("Failed to read profiles: %v", err)
+		}
+		if err := json.Unmarshal(data, &profiles); err != nil {
+			log.Fatalf("Failed to parse profiles: %v", err)
+		}
+	}
+
+	// Flags that were given override every marketplace's profile
+	flag.Visit(func(f *flag.Flag) {
+		for mp, profile := range profiles {
+			switch f.Name {
+			case "latency":
+				profile.LatencyMS, profile.JitterMS = int(latency.Milliseconds()), 0
+			case "error-rate":
+				profile.ErrorRate = *errorRate
+			case "reject-rate":
+				profile.RejectRate = *rejectRate
+			case "rate-limit":
+				profile.RateLimit = *rateLimit
+			}
+			profiles[mp] = profile
+		}
+	})
+	for mp, url := range webhooks {
+		profile := profiles[mp]
+		profile.WebhookURL = url
+		profiles[mp] = profile
+	}
+
+	mock := marketplace.NewMock(profiles, *secret, *seed)
+	for mp, profile := range profiles {
+		log.Printf("🏪 %s: %+v", mp, profile)
+	}
+
+	if *orderInterval > 0 {
+		go mock.GenerateOrders(context.Background(), *orderInterval)
+	}
+
+	log.Printf("✅ Listening on %s", *addr)
+	log.Fatal(http.ListenAndServe(*addr, mock.Handler()))
+}
+
+func getEnv(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
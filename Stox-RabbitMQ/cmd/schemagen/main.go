@@ -0,0 +1,24 @@
+package main
+
+import (
+	"flag"
+	"log"
+
+	"stox-rabbitmq/internal/schema"
+)
+
+// schemagen writes JSON Schemas for all Stox message contracts so that
+// producers written in other languages can validate their payloads.
+func main() {
+	out := flag.String("out", "schemas", "directory to write *.schema.json files into")
+	flag.Parse()
+
+	written, err := schema.WriteJSONSchemas(*out)
+	if err != nil {
+		log.Fatalf("Failed to generate schemas: %v", err)
+	}
+
+	for _, path := range written {
+		log.Printf("📄 Wrote %s", path)
+	}
+}
@@ -2,4 +2,7 @@ module stox-rabbitmq
 
 go 1.23
 
-require github.com/rabbitmq/amqp091-go v1.10.0
+require (
+	github.com/klauspost/compress v1.18.0
+	github.com/rabbitmq/amqp091-go v1.10.0
+)
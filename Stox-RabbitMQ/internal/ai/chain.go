@@ -0,0 +1,90 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// DefaultTimeout bounds a single provider call when no timeout is configured
+const DefaultTimeout = 30 * time.Second
+
+// Chain tries providers in order until one succeeds
+type Chain struct {
+	providers []Provider
+	timeout   time.Duration
+	ledger    *Ledger
+}
+
+// NewChain creates a fallback chain; it is itself a Provider
+func NewChain(timeout time.Duration, providers ...Provider) *Chain {
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Chain{providers: providers, timeout: timeout, ledger: NewLedger()}
+}
+
+// Name lists the providers in fallback order
+func (c *Chain) Name() string {
+	name := "chain("
+	for i, p := range c.providers {
+		if i > 0 {
+			name += ","
+		}
+		name += p.Name()
+	}
+	return name + ")"
+}
+
+// Ledger returns the usage recorded by this chain
+func (c *Chain) Ledger() *Ledger {
+	return c.ledger
+}
+
+// EnhanceImage enhances an image with the first provider that succeeds
+func (c *Chain) EnhanceImage(ctx context.Context, req ImageRequest) (ImageResult, error) {
+	var errs []error
+	for _, p := range c.providers {
+		callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		result, err := p.EnhanceImage(callCtx, req)
+		cancel()
+
+		if err == nil {
+			c.ledger.Record(result.Usage)
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		if !errors.Is(err, ErrNotSupported) {
+			log.Printf("⚠️  AI provider %s failed to enhance image %s: %v", p.Name(), req.ImageID, err)
+		}
+	}
+	return ImageResult{}, fmt.Errorf("all AI providers failed: %w", errors.Join(errs...))
+}
+
+// GenerateText generates text with the first provider that succeeds
+func (c *Chain) GenerateText(ctx context.Context, req TextRequest) (TextResult, error) {
+	var errs []error
+	for _, p := range c.providers {
+		callCtx, cancel := context.WithTimeout(ctx, c.timeout)
+		result, err := p.GenerateText(callCtx, req)
+		cancel()
+
+		if err == nil {
+			c.ledger.Record(result.Usage)
+			return result, nil
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", p.Name(), err))
+		if ctx.Err() != nil {
+			break
+		}
+		if !errors.Is(err, ErrNotSupported) {
+			log.Printf("⚠️  AI provider %s failed to generate %s text: %v", p.Name(), req.Task, err)
+		}
+	}
+	return TextResult{}, fmt.Errorf("all AI providers failed: %w", errors.Join(errs...))
+}
@@ -0,0 +1,224 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+)
+
+// HTTPConfig points the HTTP provider at an inference server
+type HTTPConfig struct {
+	URL    string // base URL, e.g. http://localhost:8090
+	APIKey string // sent as a bearer token when set
+	Model  string
+}
+
+// HTTPProvider calls an inference server speaking the JSON protocol served by Handler
+type HTTPProvider struct {
+	config  HTTPConfig
+	pricing Pricing
+	client  *http.Client
+}
+
+// NewHTTPProvider creates an HTTP provider; timeouts come from the caller's context
+func NewHTTPProvider(config HTTPConfig, pricing Pricing) (*HTTPProvider, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("http AI provider requires a URL")
+	}
+	config.URL = strings.TrimSuffix(config.URL, "/")
+	return &HTTPProvider{config: config, pricing: pricing, client: &http.Client{}}, nil
+}
+
+// Name identifies the provider in usage records
+func (p *HTTPProvider) Name() string { return "http" }
+
+// Wire types shared by HTTPProvider and Handler
+type (
+	enhanceRequest struct {
+		Model     string `json:"model,omitempty"`
+		ProductID string `json:"product_id"`
+		ImageID   string `json:"image_id"`
+		Image     []byte `json:"image"`
+	}
+	enhanceResponse struct {
+		Image        []byte   `json:"image"`
+		Enhancements []string `json:"enhancements"`
+		Usage        Usage    `json:"usage"`
+	}
+	generateRequest struct {
+		Model     string            `json:"model,omitempty"`
+		Task      string            `json:"task"`
+		Prompt    string            `json:"prompt"`
+		Input     map[string]string `json:"input,omitempty"`
+		MaxTokens int               `json:"max_tokens,omitempty"`
+	}
+	generateResponse struct {
+		Text  string `json:"text"`
+		Usage Usage  `json:"usage"`
+	}
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// EnhanceImage posts the image to /v1/enhance
+func (p *HTTPProvider) EnhanceImage(ctx context.Context, req ImageRequest) (ImageResult, error) {
+	var resp enhanceResponse
+	err := p.post(ctx, "/v1/enhance", enhanceRequest{
+		Model:     p.config.Model,
+		ProductID: req.ProductID,
+		ImageID:   req.ImageID,
+		Image:     req.Image,
+	}, &resp)
+	if err != nil {
+		return ImageResult{}, err
+	}
+
+	return ImageResult{
+		Image:        resp.Image,
+		Enhancements: resp.Enhancements,
+		Usage:        p.meter(resp.Usage, 1),
+	}, nil
+}
+
+// GenerateText posts the task to /v1/generate
+func (p *HTTPProvider) GenerateText(ctx context.Context, req TextRequest) (TextResult, error) {
+	var resp generateResponse
+	err := p.post(ctx, "/v1/generate", generateRequest{
+		Model:     p.config.Model,
+		Task:      req.Task,
+		Prompt:    req.Prompt,
+		Input:     req.Input,
+		MaxTokens: req.MaxTokens,
+	}, &resp)
+	if err != nil {
+		return TextResult{}, err
+	}
+
+	usage := resp.Usage
+	if usage.InputTokens == 0 && usage.OutputTokens == 0 { // server did not meter
+		usage.InputTokens, usage.OutputTokens = estimateTokens(req.Prompt), estimateTokens(resp.Text)
+	}
+	return TextResult{Text: resp.Text, Usage: p.meter(usage, 0)}, nil
+}
+
+// meter attributes server-reported usage to this provider and prices it
+func (p *HTTPProvider) meter(u Usage, images int) Usage {
+	u.Provider = p.Name()
+	if u.Model == "" {
+		u.Model = p.config.Model
+	}
+	if u.Images == 0 {
+		u.Images = images
+	}
+	u.Cost = p.pricing.Cost(u.InputTokens, u.OutputTokens, u.Images)
+	return u
+}
+
+func (p *HTTPProvider) post(ctx context.Context, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.URL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if p.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotImplemented {
+		return ErrNotSupported
+	}
+	if resp.StatusCode != http.StatusOK {
+		var e errorResponse
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(raw, &e) == nil && e.Error != "" {
+			return fmt.Errorf("%s: %s", resp.Status, e.Error)
+		}
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
+
+// Handler serves a provider over the HTTP protocol, e.g. to run the mock as a
+// local stand-in for a real inference server
+func Handler(provider Provider) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("POST /v1/enhance", func(w http.ResponseWriter, r *http.Request) {
+		var req enhanceRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := provider.EnhanceImage(r.Context(), ImageRequest{
+			ProductID: req.ProductID,
+			ImageID:   req.ImageID,
+			Image:     req.Image,
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, enhanceResponse{Image: result.Image, Enhancements: result.Enhancements, Usage: result.Usage})
+	})
+
+	mux.HandleFunc("POST /v1/generate", func(w http.ResponseWriter, r *http.Request) {
+		var req generateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+
+		result, err := provider.GenerateText(r.Context(), TextRequest{
+			Task:      req.Task,
+			Prompt:    req.Prompt,
+			Input:     req.Input,
+			MaxTokens: req.MaxTokens,
+		})
+		if err != nil {
+			writeError(w, err)
+			return
+		}
+		writeJSON(w, http.StatusOK, generateResponse{Text: result.Text, Usage: result.Usage})
+	})
+
+	return mux
+}
+
+func writeError(w http.ResponseWriter, err error) {
+	status := http.StatusInternalServerError
+	if errors.Is(err, ErrNotSupported) {
+		status = http.StatusNotImplemented
+	}
+	writeJSON(w, status, errorResponse{Error: err.Error()})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
@@ -0,0 +1,150 @@
+package ai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"stox-rabbitmq/internal/imaging"
+	"stox-rabbitmq/internal/seo"
+)
+
+// Mock is a deterministic local provider: images go through the imaging
+// pipeline and text comes from templates, so results only depend on the input
+type Mock struct {
+	pricing Pricing
+}
+
+// NewMock creates the local provider
+func NewMock(pricing Pricing) *Mock {
+	return &Mock{pricing: pricing}
+}
+
+// Name identifies the provider in usage records
+func (m *Mock) Name() string { return "mock" }
+
+// EnhanceImage runs the local imaging pipeline
+func (m *Mock) EnhanceImage(ctx context.Context, req ImageRequest) (ImageResult, error) {
+	src, err := imaging.Decode(req.Image)
+	if err != nil {
+		return ImageResult{}, err
+	}
+
+	enhanced, steps, err := imaging.Enhance(src)
+	if err != nil {
+		return ImageResult{}, err
+	}
+
+	data, err := imaging.EncodeJPEG(enhanced, 90)
+	if err != nil {
+		return ImageResult{}, err
+	}
+
+	return ImageResult{
+		Image:        data,
+		Enhancements: steps,
+		Usage: Usage{
+			Provider: m.Name(),
+			Model:    "local-imaging",
+			Images:   1,
+			Cost:     m.pricing.Cost(0, 0, 1),
+		},
+	}, ctx.Err()
+}
+
+// GenerateText fills task templates from the request input
+func (m *Mock) GenerateText(ctx context.Context, req TextRequest) (TextResult, error) {
+	var text string
+	switch req.Task {
+	case TaskSEO:
+		out, err := json.Marshal(mockSEO(req.Input))
+		if err != nil {
+			return TextResult{}, err
+		}
+		text = string(out)
+	default:
+		return TextResult{}, fmt.Errorf("%w: task %q", ErrNotSupported, req.Task)
+	}
+
+	in, out := estimateTokens(req.Prompt), estimateTokens(text)
+	return TextResult{
+		Text: text,
+		Usage: Usage{
+			Provider:     m.Name(),
+			Model:        "templates",
+			InputTokens:  in,
+			OutputTokens: out,
+			Cost:         m.pricing.Cost(in, out, 0),
+		},
+	}, ctx.Err()
+}
+
+// SEOContent is the JSON shape of TaskSEO output
+type SEOContent struct {
+	Title       string   `json:"title"`
+	Description string   `json:"description"`
+	Keywords    []string `json:"keywords"`
+}
+
+// seoTemplates are the mock's localized copy; product text itself is not translated.
+// They avoid the promotional phrases marketplaces reject (see listing.Policies).
+type seoTemplates struct {
+	electronicsTitle string
+	wearablesTitle   string
+	description      string
+	options          string // appended for products with variants
+}
+
+var mockTemplates = map[string]seoTemplates{
+	seo.LocaleEN: {
+		electronicsTitle: "%s - Premium Quality with 2-Year Warranty",
+		wearablesTitle:   "%s - Advanced Fitness and Health Tracking",
+		description: "%s. %s. 30-day return policy and 2-year warranty included. " +
+			"Trusted by thousands of customers worldwide. Order now for fast delivery!",
+		options: " Available options: %s.",
+	},
+	seo.LocaleTR: {
+		electronicsTitle: "%s - Yüksek Kalite, 2 Yıl Garantili",
+		wearablesTitle:   "%s - Gelişmiş Fitness ve Sağlık Takibi",
+		description: "%s. %s. 30 gün iade hakkı ve 2 yıl garanti dahil. " +
+			"Binlerce müşterinin tercihi. Hızlı teslimat için hemen sipariş verin!",
+		options: " Seçenekler: %s.",
+	},
+	seo.LocaleDE: {
+		electronicsTitle: "%s - Premium-Qualität mit 2 Jahren Garantie",
+		wearablesTitle:   "%s - Fortschrittliches Fitness- und Gesundheits-Tracking",
+		description: "%s. %s. 30 Tage Rückgaberecht und 2 Jahre Garantie inklusive. " +
+			"Tausende zufriedene Kunden weltweit. Jetzt bestellen und schnell erhalten!",
+		options: " Verfügbare Optionen: %s.",
+	},
+}
+
+func mockSEO(input map[string]string) SEOContent {
+	locale := input["locale"]
+	tmpl, ok := mockTemplates[locale]
+	if !ok {
+		locale, tmpl = seo.DefaultLocale, mockTemplates[seo.DefaultLocale]
+	}
+	kind := strings.ToLower(input["category"]) // template selection is locale independent
+	category := seo.Lower(locale, input["category"])
+
+	// Generate SEO-optimized title
+	title := input["title"]
+	if kind == "electronics" {
+		title = fmt.Sprintf(tmpl.electronicsTitle, input["title"])
+	} else if kind == "wearables" {
+		title = fmt.Sprintf(tmpl.wearablesTitle, input["title"])
+	}
+
+	// Generate SEO description
+	description := fmt.Sprintf(tmpl.description, input["title"], input["description"])
+	if options := input["options"]; options != "" && options != "none" {
+		description += fmt.Sprintf(tmpl.options, options)
+	}
+
+	// Keywords are extracted from the content by seo-service; only suggest the basics
+	keywords := []string{seo.Lower(locale, input["title"]), category}
+
+	return SEOContent{Title: title, Description: description, Keywords: keywords}
+}
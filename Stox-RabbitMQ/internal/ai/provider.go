@@ -0,0 +1,88 @@
+// Package ai abstracts the AI backends used for image enhancement and text generation.
+//
+// Services talk to a Chain, which tries the configured providers in order with a
+// per-call timeout and records token and cost usage for every successful call.
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotSupported is returned by providers that do not implement a capability
+var ErrNotSupported = errors.New("not supported by provider")
+
+// Text generation tasks
+const (
+	TaskSEO = "seo" // Input: title, description, category, locale; Output: JSON {title, description, keywords}
+)
+
+// ImageRequest asks a provider to enhance one image
+type ImageRequest struct {
+	ProductID string
+	ImageID   string
+	Image     []byte // original bytes (JPEG, PNG or GIF)
+}
+
+// ImageResult is an enhanced image as JPEG
+type ImageResult struct {
+	Image        []byte
+	Enhancements []string
+	Usage        Usage
+}
+
+// TextRequest asks a provider to generate text for a task
+type TextRequest struct {
+	Task      string
+	Prompt    string
+	Input     map[string]string
+	MaxTokens int
+}
+
+// TextResult is generated text
+type TextResult struct {
+	Text  string
+	Usage Usage
+}
+
+// Provider is an AI backend
+type Provider interface {
+	Name() string
+	EnhanceImage(ctx context.Context, req ImageRequest) (ImageResult, error)
+	GenerateText(ctx context.Context, req TextRequest) (TextResult, error)
+}
+
+// Config selects providers and their settings
+type Config struct {
+	Providers []string      // fallback order, e.g. ["http", "mock"]
+	Timeout   time.Duration // per provider call
+	HTTP      HTTPConfig
+	Pricing   Pricing
+}
+
+// New builds a Chain from the configured provider names
+func New(config Config) (*Chain, error) {
+	if len(config.Providers) == 0 {
+		config.Providers = []string{"mock"}
+	}
+
+	var providers []Provider
+	for _, name := range config.Providers {
+		switch name {
+		case "mock":
+			providers = append(providers, NewMock(config.Pricing))
+		case "http":
+			p, err := NewHTTPProvider(config.HTTP, config.Pricing)
+			if err != nil {
+				return nil, err
+			}
+			providers = append(providers, p)
+		default:
+			return nil, fmt.Errorf("unknown AI provider %q", name)
+		}
+	}
+
+	return NewChain(config.Timeout, providers...), nil
+}
@@ -0,0 +1,108 @@
+package ai
+
+import (
+	"log"
+	"sort"
+	"sync"
+	"time"
+)
+
+// Usage is the metered cost of one provider call
+type Usage struct {
+	Provider     string  `json:"provider"`
+	Model        string  `json:"model,omitempty"`
+	InputTokens  int     `json:"input_tokens"`
+	OutputTokens int     `json:"output_tokens"`
+	Images       int     `json:"images"`
+	Cost         float64 `json:"cost"` // USD
+}
+
+// Pricing converts usage into cost
+type Pricing struct {
+	InputPer1K  float64 // USD per 1000 input tokens
+	OutputPer1K float64 // USD per 1000 output tokens
+	PerImage    float64 // USD per enhanced image
+}
+
+// Cost prices token and image counts
+func (p Pricing) Cost(inputTokens, outputTokens, images int) float64 {
+	return float64(inputTokens)/1000*p.InputPer1K +
+		float64(outputTokens)/1000*p.OutputPer1K +
+		float64(images)*p.PerImage
+}
+
+// estimateTokens approximates a token count as one token per four characters
+func estimateTokens(text string) int {
+	return (len([]rune(text)) + 3) / 4
+}
+
+// UsageTotal aggregates usage for one provider
+type UsageTotal struct {
+	Calls        int64
+	InputTokens  int64
+	OutputTokens int64
+	Images       int64
+	Cost         float64
+}
+
+// Ledger accumulates usage per provider
+type Ledger struct {
+	mu     sync.Mutex
+	totals map[string]*UsageTotal
+}
+
+// NewLedger creates an empty ledger
+func NewLedger() *Ledger {
+	return &Ledger{totals: make(map[string]*UsageTotal)}
+}
+
+// Record adds one call's usage
+func (l *Ledger) Record(u Usage) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	total, ok := l.totals[u.Provider]
+	if !ok {
+		total = &UsageTotal{}
+		l.totals[u.Provider] = total
+	}
+	total.Calls++
+	total.InputTokens += int64(u.InputTokens)
+	total.OutputTokens += int64(u.OutputTokens)
+	total.Images += int64(u.Images)
+	total.Cost += u.Cost
+}
+
+// Totals returns usage keyed by provider
+func (l *Ledger) Totals() map[string]UsageTotal {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	out := make(map[string]UsageTotal, len(l.totals))
+	for k, v := range l.totals {
+		out[k] = *v
+	}
+	return out
+}
+
+// Report logs usage totals at the given interval
+func (l *Ledger) Report(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		totals := l.Totals()
+
+		names := make([]string, 0, len(totals))
+		for name := range totals {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			t := totals[name]
+			log.Printf("💰 AI %s: %d calls, %d in / %d out tokens, %d images, $%.4f",
+				name, t.Calls, t.InputTokens, t.OutputTokens, t.Images, t.Cost)
+		}
+	}
+}
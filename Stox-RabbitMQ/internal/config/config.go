@@ -3,13 +3,93 @@ package config
 import (
 	"fmt"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"stox-rabbitmq/internal/ai"
+	"stox-rabbitmq/internal/storage"
 )
 
 // Config holds all configuration for the Stox platform
 type Config struct {
-	RabbitMQ    RabbitMQConfig
-	ServiceName string
-	LogLevel    string
+	RabbitMQ      RabbitMQConfig
+	ServiceName   string
+	LogLevel      string
+	EventStoreDir string
+
+	// ValidateSchemas enables JSON Schema checks on publish and consume
+	ValidateSchemas bool
+
+	// SyncContentType is the wire format for stox.sync traffic
+	// (application/json, application/msgpack or application/x-protobuf)
+	SyncContentType string
+
+	// Compression is the content-encoding for large published messages ("" disables it)
+	Compression          string
+	CompressionThreshold int
+
+	// MaxDecompressedSize caps consumed message bodies after decompression
+	MaxDecompressedSize int64
+
+	// ClaimCheckDir is the shared blob directory for oversized payloads ("" disables claim checks)
+	ClaimCheckDir       string
+	ClaimCheckThreshold int
+	ClaimCheckTTL       time.Duration
+
+	// Storage is the blob store for product images
+	Storage storage.Config
+
+	// CDNBaseURL prefixes storage keys to build public image URLs
+	CDNBaseURL string
+
+	// AI selects the providers used for image enhancement and text generation
+	AI ai.Config
+
+	// SEOLocales are the locales SEO content is generated in
+	SEOLocales []string
+
+	// SEOCorpusDir holds extra <category>.txt keyword corpus files ("" uses the built-in corpus only)
+	SEOCorpusDir string
+
+	// TaxonomyDir holds category tree and mapping files overriding the built-in ones ("" uses the built-ins)
+	TaxonomyDir string
+
+	// MarketplaceRegistry is a file of enabled marketplaces and connected seller accounts ("" uses the built-in one)
+	MarketplaceRegistry string
+
+	// Marketplaces holds API access and order webhook settings per marketplace
+	Marketplaces map[string]MarketplaceConfig
+
+	// OrderPollInterval is how often marketplace services poll for new orders
+	OrderPollInterval time.Duration
+
+	// WebhookAddr is the listen address for order webhooks ("" uses the service's default)
+	WebhookAddr string
+
+	// Marketplace API circuit breakers open after BreakerThreshold consecutive
+	// failures and probe again after BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Prefetch limits unacknowledged deliveries per consumer in marketplace services
+	Prefetch int
+
+	// Sync updates are coalesced into bulk API calls of up to SyncBatchSize
+	// updates collected within SyncBatchWindow
+	SyncBatchSize   int
+	SyncBatchWindow time.Duration
+
+	// SyncPartitions splits each marketplace's sync queue by product so
+	// replicas can share the work without reordering a product's updates
+	SyncPartitions int
+}
+
+// MarketplaceConfig holds one marketplace's API access
+type MarketplaceConfig struct {
+	APIURL        string // marketplace API base URL for listings, stock, prices and order polling
+	WebhookSecret string // HMAC secret order webhooks are signed with; empty disables them
+	RateLimits    string // per-endpoint overrides, e.g. "inventory=2/4,prices=1"
 }
 
 // RabbitMQConfig holds RabbitMQ connection details
@@ -31,8 +111,79 @@ func LoadConfig() *Config {
 			Host:     getEnv("RABBITMQ_HOST", "localhost"),
 			Port:     getEnv("RABBITMQ_PORT", "5672"),
 		},
-		ServiceName: getEnv("SERVICE_NAME", "stox-service"),
-		LogLevel:    getEnv("LOG_LEVEL", "info"),
+		ServiceName:   getEnv("SERVICE_NAME", "stox-service"),
+		LogLevel:      getEnv("LOG_LEVEL", "info"),
+		EventStoreDir: getEnv("EVENT_STORE_DIR", "./data/events"),
+
+		ValidateSchemas: getEnv("SCHEMA_VALIDATION", "false") == "true",
+		SyncContentType: getEnv("SYNC_CONTENT_TYPE", "application/json"),
+
+		Compression:          getEnv("COMPRESSION", ""),
+		CompressionThreshold: getEnvInt("COMPRESSION_THRESHOLD", 4096),
+		MaxDecompressedSize:  int64(getEnvInt("MAX_DECOMPRESSED_SIZE", 64<<20)),
+
+		ClaimCheckDir:       getEnv("CLAIM_CHECK_DIR", ""),
+		ClaimCheckThreshold: getEnvInt("CLAIM_CHECK_THRESHOLD", 256*1024),
+		ClaimCheckTTL:       getEnvDuration("CLAIM_CHECK_TTL", 24*time.Hour),
+
+		Storage: storage.Config{
+			Backend: getEnv("STORAGE_BACKEND", "filesystem"),
+			Dir:     getEnv("STORAGE_DIR", "./data/blobs"),
+			S3: storage.S3Config{
+				Endpoint:  getEnv("S3_ENDPOINT", ""),
+				Region:    getEnv("S3_REGION", "us-east-1"),
+				Bucket:    getEnv("S3_BUCKET", "stox-images"),
+				AccessKey: getEnv("S3_ACCESS_KEY", ""),
+				SecretKey: getEnv("S3_SECRET_KEY", ""),
+			},
+		},
+		CDNBaseURL: getEnv("CDN_BASE_URL", "https://cdn.stox.com"),
+
+		AI: ai.Config{
+			Providers: getEnvList("AI_PROVIDERS", []string{"mock"}),
+			Timeout:   getEnvDuration("AI_TIMEOUT", 30*time.Second),
+			HTTP: ai.HTTPConfig{
+				URL:    getEnv("AI_HTTP_URL", ""),
+				APIKey: getEnv("AI_HTTP_API_KEY", ""),
+				Model:  getEnv("AI_MODEL", ""),
+			},
+			Pricing: ai.Pricing{
+				InputPer1K:  getEnvFloat("AI_PRICE_INPUT_PER_1K", 0),
+				OutputPer1K: getEnvFloat("AI_PRICE_OUTPUT_PER_1K", 0),
+				PerImage:    getEnvFloat("AI_PRICE_PER_IMAGE", 0),
+			},
+		},
+		SEOLocales:   getEnvList("SEO_LOCALES", []string{"tr-TR", "en-US", "de-DE"}),
+		SEOCorpusDir: getEnv("SEO_CORPUS_DIR", ""),
+		TaxonomyDir:  getEnv("TAXONOMY_DIR", ""),
+
+		MarketplaceRegistry: getEnv("MARKETPLACE_REGISTRY", ""),
+
+		Marketplaces: map[string]MarketplaceConfig{
+			"amazon":      marketplaceConfig("amazon"),
+			"trendyol":    marketplaceConfig("trendyol"),
+			"hepsiburada": marketplaceConfig("hepsiburada"),
+		},
+		OrderPollInterval: getEnvDuration("ORDER_POLL_INTERVAL", time.Minute),
+		WebhookAddr:       getEnv("WEBHOOK_ADDR", ""),
+		BreakerThreshold:  getEnvInt("BREAKER_THRESHOLD", 5),
+		BreakerCooldown:   getEnvDuration("BREAKER_COOLDOWN", 30*time.Second),
+		Prefetch:          getEnvInt("PREFETCH", 10),
+		SyncBatchSize:     getEnvInt("SYNC_BATCH_SIZE", 50),
+		SyncBatchWindow:   getEnvDuration("SYNC_BATCH_WINDOW", 2*time.Second),
+		SyncPartitions:    getEnvInt("SYNC_PARTITIONS", 1),
+	}
+}
+
+// marketplaceConfig reads <MARKETPLACE>_API_URL, <MARKETPLACE>_WEBHOOK_SECRET and
+// <MARKETPLACE>_RATE_LIMITS; the API defaults to cmd/mock-marketplace on localhost.
+// There is no default secret: without one, order webhooks are disabled.
+func marketplaceConfig(marketplace string) MarketplaceConfig {
+	prefix := strings.ToUpper(marketplace)
+	return MarketplaceConfig{
+		APIURL:        getEnv(prefix+"_API_URL", "http://localhost:9090/"+marketplace),
+		WebhookSecret: getEnv(prefix+"_WEBHOOK_SECRET", ""),
+		RateLimits:    getEnv(prefix+"_RATE_LIMITS", ""),
 	}
 }
 
@@ -56,3 +207,41 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+// getEnvDuration gets a duration environment variable (e.g. "24h") or returns a default value
+func getEnvDuration(key string, defaultValue time.Duration) time.Duration {
+	if value, err := time.ParseDuration(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvFloat gets a float environment variable or returns a default value
+func getEnvFloat(key string, defaultValue float64) float64 {
+	if value, err := strconv.ParseFloat(os.Getenv(key), 64); err == nil {
+		return value
+	}
+	return defaultValue
+}
+
+// getEnvList gets a comma separated environment variable or returns a default value
+func getEnvList(key string, defaultValue []string) []string {
+	var values []string
+	for _, v := range strings.Split(os.Getenv(key), ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	if len(values) == 0 {
+		return defaultValue
+	}
+	return values
+}
+
+// getEnvInt gets an integer environment variable or returns a default value
+func getEnvInt(key string, defaultValue int) int {
+	if value, err := strconv.Atoi(os.Getenv(key)); err == nil {
+		return value
+	}
+	return defaultValue
+}
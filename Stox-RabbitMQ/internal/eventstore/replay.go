@@ -0,0 +1,53 @@
+package eventstore
+
+import (
+	"context"
+	"fmt"
+	"log"
+)
+
+// HeaderReplayed marks messages published by Replay. The archiver skips them,
+// so replaying does not archive the events a second time.
+const HeaderReplayed = "x-replayed"
+
+// Publisher is the subset of rabbitmq.Client used for replays
+type Publisher interface {
+	PublishMessageWithHeaders(exchange, routingKey string, message interface{}, headers map[string]interface{}) error
+}
+
+// Replayed reports whether a message's headers mark it as replayed
+func Replayed(headers map[string]interface{}) bool {
+	replayed, _ := headers[HeaderReplayed].(bool)
+	return replayed
+}
+
+// Replay republishes every record matching the filter in timestamp order,
+// marked with HeaderReplayed. An empty exchange republishes each record to
+// the exchange it was archived from.
+func (s *Store) Replay(ctx context.Context, publisher Publisher, filter Filter, exchange string) (int, error) {
+	records, err := s.Query(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	replayed := 0
+	for _, r := range records {
+		if err := ctx.Err(); err != nil {
+			return replayed, err
+		}
+
+		target := exchange
+		if target == "" {
+			target = r.Exchange
+		}
+
+		headers := map[string]interface{}{HeaderReplayed: true}
+		if err := publisher.PublishMessageWithHeaders(target, r.RoutingKey, r.Event, headers); err != nil {
+			return replayed, fmt.Errorf("failed to replay event %s: %w", r.Event.ID, err)
+		}
+		replayed++
+	}
+
+	log.Printf("⏪ Replayed %d event(s)", replayed)
+	return replayed, nil
+}
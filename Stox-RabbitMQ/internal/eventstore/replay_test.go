@@ -0,0 +1,59 @@
+package eventstore
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stox-rabbitmq/internal/models"
+)
+
+// testPublisher records replayed messages and their headers
+type testPublisher struct {
+	published []Record
+	headers   []map[string]interface{}
+}
+
+func (p *testPublisher) PublishMessageWithHeaders(exchange, routingKey string, message interface{}, headers map[string]interface{}) error {
+	p.published = append(p.published, Record{Exchange: exchange, RoutingKey: routingKey, Event: message.(models.ProcessingEvent)})
+	p.headers = append(p.headers, headers)
+	return nil
+}
+
+func TestReplayMarksEventsAsReplayed(t *testing.T) {
+	store, err := Open(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	at := time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC)
+	records := []Record{
+		{Exchange: "stox.listings", Event: models.ProcessingEvent{ID: "evt_2", Type: models.EventMarketplaceListed, ProductID: "prod_1", Timestamp: at.Add(time.Minute)}},
+		{Exchange: "stox.images", RoutingKey: "event.ai_enhanced", Event: models.ProcessingEvent{ID: "evt_1", Type: "ai_enhanced", ProductID: "prod_1", Timestamp: at}},
+		{Exchange: "stox.images", RoutingKey: "event.ai_enhanced", Event: models.ProcessingEvent{ID: "evt_3", Type: "ai_enhanced", ProductID: "prod_2", Timestamp: at}},
+	}
+	for _, r := range records {
+		if err := store.Append(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publisher := &testPublisher{}
+	n, err := store.Replay(context.Background(), publisher, Filter{ProductID: "prod_1"}, "")
+	if err != nil || n != 2 {
+		t.Fatalf("replayed %d (%v), want 2", n, err)
+	}
+
+	want := []string{"evt_1 stox.images", "evt_2 stox.listings"}
+	for i, r := range publisher.published {
+		if got := r.Event.ID + " " + r.Exchange; got != want[i] {
+			t.Errorf("replay %d was %s, want %s", i, got, want[i])
+		}
+		if !Replayed(publisher.headers[i]) {
+			t.Errorf("replay of %s is not marked as replayed: %v", r.Event.ID, publisher.headers[i])
+		}
+	}
+
+	if Replayed(nil) || Replayed(map[string]interface{}{HeaderReplayed: "yes"}) {
+		t.Error("unmarked headers reported as replayed")
+	}
+}
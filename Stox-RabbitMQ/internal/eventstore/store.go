@@ -0,0 +1,167 @@
+// Package eventstore is an append-only archive of ProcessingEvents.
+//
+// Events are written as JSON lines into one segment file per UTC day
+// (events-20250101.jsonl). Segments are never rewritten, which keeps the
+// store safe to back up or ship elsewhere while the archiver is running.
+package eventstore
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"stox-rabbitmq/internal/models"
+)
+
+const segmentLayout = "20060102"
+
+// Record is one archived event plus where it was originally published
+type Record struct {
+	Exchange   string                 `json:"exchange"`
+	RoutingKey string                 `json:"routing_key"`
+	ArchivedAt time.Time              `json:"archived_at"`
+	Event      models.ProcessingEvent `json:"event"`
+}
+
+// Filter selects records; zero values match everything
+type Filter struct {
+	ProductID string
+	Type      string
+	From      time.Time // inclusive, compared against Event.Timestamp
+	To        time.Time // exclusive
+}
+
+// Match reports whether a record satisfies the filter
+func (f Filter) Match(r Record) bool {
+	if f.ProductID != "" && r.Event.ProductID != f.ProductID {
+		return false
+	}
+	if f.Type != "" && r.Event.Type != f.Type {
+		return false
+	}
+	if !f.From.IsZero() && r.Event.Timestamp.Before(f.From) {
+		return false
+	}
+	if !f.To.IsZero() && !r.Event.Timestamp.Before(f.To) {
+		return false
+	}
+	return true
+}
+
+// Store appends records to daily JSONL segments in a directory
+type Store struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// Open creates the store directory if needed
+func Open(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create event store directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Append writes a record to the segment of the day it was archived
+func (s *Store) Append(record Record) error {
+	if record.ArchivedAt.IsZero() {
+		record.ArchivedAt = time.Now()
+	}
+
+	line, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("failed to marshal record: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	path := s.segmentPath(record.ArchivedAt)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open segment %s: %w", path, err)
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return fmt.Errorf("failed to append to segment %s: %w", path, err)
+	}
+
+	return f.Sync()
+}
+
+// Query returns all records matching the filter, ordered by event timestamp
+func (s *Store) Query(filter Filter) ([]Record, error) {
+	segments, err := s.segments()
+	if err != nil {
+		return nil, err
+	}
+
+	var records []Record
+	for _, path := range segments {
+		if err := readSegment(path, func(r Record) {
+			if filter.Match(r) {
+				records = append(records, r)
+			}
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Event.Timestamp.Before(records[j].Event.Timestamp)
+	})
+
+	return records, nil
+}
+
+// segmentPath returns the file a record archived at t belongs to
+func (s *Store) segmentPath(t time.Time) string {
+	return filepath.Join(s.dir, fmt.Sprintf("events-%s.jsonl", t.UTC().Format(segmentLayout)))
+}
+
+// segments lists segment files in chronological order
+func (s *Store) segments() ([]string, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "events-*.jsonl"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to list segments: %w", err)
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// readSegment calls fn for every record in a segment, skipping a torn last line
+func readSegment(path string, fn func(Record)) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open segment %s: %w", path, err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var r Record
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			continue // partially written line after a crash
+		}
+		fn(r)
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read segment %s: %w", path, err)
+	}
+	return nil
+}
@@ -0,0 +1,171 @@
+package imaging
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif" // register decoders
+	_ "image/jpeg"
+	_ "image/png"
+	"sort"
+)
+
+// Supported image formats, spelled the way models.Image.Format carries them
+const (
+	FormatJPEG = "JPEG"
+	FormatPNG  = "PNG"
+	FormatGIF  = "GIF"
+	FormatWebP = "WEBP"
+)
+
+// ErrUnsupportedFormat is returned for data that is not JPEG, PNG, GIF or WebP
+var ErrUnsupportedFormat = errors.New("unsupported image format")
+
+// ErrTooManyPixels is returned for images larger than MaxPixels
+var ErrTooManyPixels = errors.New("image has too many pixels")
+
+// MaxPixels caps width x height of any image that is decoded. A small file can
+// declare huge dimensions, and decoding allocates for all of them.
+const MaxPixels = 64 << 20 // 8192x8192
+
+// Metadata describes an image as decoded from its bytes
+type Metadata struct {
+	Format string
+	Width  int
+	Height int
+	Size   int64
+	Hash   string // sha256 of the raw bytes, used for deduplication
+}
+
+// Inspect decodes an image and returns its real format, dimensions and content hash.
+// JPEG, PNG and GIF are fully decoded so truncated files are caught. The standard
+// library has no WebP decoder, so WebP is verified from its RIFF headers only.
+func Inspect(data []byte) (Metadata, error) {
+	sum := sha256.Sum256(data)
+	meta := Metadata{Size: int64(len(data)), Hash: hex.EncodeToString(sum[:])}
+
+	if isWebP(data) {
+		width, height, err := webpSize(data)
+		if err != nil {
+			return meta, err
+		}
+		meta.Format, meta.Width, meta.Height = FormatWebP, width, height
+		return meta, checkPixels(width, height)
+	}
+
+	if _, err := decodeConfig(data); err != nil {
+		return meta, err
+	}
+	img, format, err := image.Decode(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return meta, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return meta, fmt.Errorf("corrupt %s image: %w", format, err)
+	}
+
+	bounds := img.Bounds()
+	meta.Width, meta.Height = bounds.Dx(), bounds.Dy()
+	switch format {
+	case "jpeg":
+		meta.Format = FormatJPEG
+	case "png":
+		meta.Format = FormatPNG
+	case "gif":
+		meta.Format = FormatGIF
+	default:
+		return meta, ErrUnsupportedFormat
+	}
+	return meta, nil
+}
+
+// decodeConfig reads an image's header and rejects it before decoding when
+// its dimensions exceed MaxPixels
+func decodeConfig(data []byte) (image.Config, error) {
+	config, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if errors.Is(err, image.ErrFormat) {
+		return config, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return config, fmt.Errorf("corrupt %s image: %w", format, err)
+	}
+	return config, checkPixels(config.Width, config.Height)
+}
+
+func checkPixels(width, height int) error {
+	if int64(width)*int64(height) > MaxPixels {
+		return fmt.Errorf("%w: %dx%d exceeds %d", ErrTooManyPixels, width, height, MaxPixels)
+	}
+	return nil
+}
+
+func isWebP(data []byte) bool {
+	return len(data) >= 12 && string(data[0:4]) == "RIFF" && string(data[8:12]) == "WEBP"
+}
+
+// webpSize reads the canvas size from the first VP8, VP8L or VP8X chunk
+func webpSize(data []byte) (int, int, error) {
+	if len(data) < 30 {
+		return 0, 0, fmt.Errorf("corrupt WEBP image: truncated header")
+	}
+
+	chunk, payload := string(data[12:16]), data[20:]
+	switch chunk {
+	case "VP8 ": // lossy: frame tag, start code, 14-bit dimensions
+		if payload[3] != 0x9d || payload[4] != 0x01 || payload[5] != 0x2a {
+			return 0, 0, fmt.Errorf("corrupt WEBP image: bad VP8 start code")
+		}
+		width := int(binary.LittleEndian.Uint16(payload[6:8]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(payload[8:10]) & 0x3fff)
+		return width, height, nil
+	case "VP8L": // lossless: signature byte, 14-bit width-1 and height-1
+		if payload[0] != 0x2f {
+			return 0, 0, fmt.Errorf("corrupt WEBP image: bad VP8L signature")
+		}
+		bits := binary.LittleEndian.Uint32(payload[1:5])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, nil
+	case "VP8X": // extended: 24-bit canvas width-1 and height-1
+		width := int(payload[4]) | int(payload[5])<<8 | int(payload[6])<<16
+		height := int(payload[7]) | int(payload[8])<<8 | int(payload[9])<<16
+		return width + 1, height + 1, nil
+	}
+	return 0, 0, fmt.Errorf("corrupt WEBP image: unknown chunk %q", chunk)
+}
+
+// Resolution is a minimum width and height in pixels
+type Resolution struct {
+	Width  int
+	Height int
+}
+
+// MarketplaceMinimums are the smallest images each marketplace accepts
+var MarketplaceMinimums = map[string]Resolution{
+	"amazon":      {Width: 1000, Height: 1000}, // required for zoom
+	"trendyol":    {Width: 600, Height: 800},
+	"hepsiburada": {Width: 500, Height: 500},
+}
+
+// CheckResolution lists the marketplaces whose minimum the image meets, and
+// one reason per marketplace whose minimum it misses
+func CheckResolution(meta Metadata) (qualified []string, missed []string) {
+	marketplaces := make([]string, 0, len(MarketplaceMinimums))
+	for marketplace := range MarketplaceMinimums {
+		marketplaces = append(marketplaces, marketplace)
+	}
+	sort.Strings(marketplaces)
+
+	for _, marketplace := range marketplaces {
+		min := MarketplaceMinimums[marketplace]
+		if meta.Width < min.Width || meta.Height < min.Height {
+			missed = append(missed, fmt.Sprintf("%dx%d is below the %s minimum of %dx%d",
+				meta.Width, meta.Height, marketplace, min.Width, min.Height))
+			continue
+		}
+		qualified = append(qualified, marketplace)
+	}
+	return qualified, missed
+}
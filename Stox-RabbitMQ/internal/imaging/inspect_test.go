@@ -0,0 +1,99 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/png"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestInspect(t *testing.T) {
+	data := encodePNG(t, 640, 480)
+	meta, err := Inspect(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.Format != FormatPNG || meta.Width != 640 || meta.Height != 480 || meta.Size != int64(len(data)) {
+		t.Errorf("got %+v, want a 640x480 PNG of %d bytes", meta, len(data))
+	}
+	if len(meta.Hash) != 64 {
+		t.Errorf("hash %q is not a sha256", meta.Hash)
+	}
+
+	if _, err := Inspect([]byte("not an image")); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("text inspected as %v, want ErrUnsupportedFormat", err)
+	}
+	if _, err := Inspect(data[:len(data)/2]); err == nil {
+		t.Error("truncated PNG inspected without error")
+	}
+}
+
+func TestInspectRejectsHugeDimensions(t *testing.T) {
+	huge := pngHeader(60000, 60000)
+	if _, err := Inspect(huge); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Inspect 60000x60000 returned %v, want ErrTooManyPixels", err)
+	}
+	if _, err := Decode(huge); !errors.Is(err, ErrTooManyPixels) {
+		t.Errorf("Decode 60000x60000 returned %v, want ErrTooManyPixels", err)
+	}
+}
+
+func TestCheckResolution(t *testing.T) {
+	tests := []struct {
+		width, height int
+		qualified     []string
+		missed        []string
+	}{
+		{2000, 2000, []string{"amazon", "hepsiburada", "trendyol"}, nil},
+		{800, 800, []string{"hepsiburada", "trendyol"}, []string{"amazon"}},
+		{600, 600, []string{"hepsiburada"}, []string{"amazon", "trendyol"}},
+		{400, 400, nil, []string{"amazon", "hepsiburada", "trendyol"}},
+	}
+	for _, tt := range tests {
+		qualified, missed := CheckResolution(Metadata{Width: tt.width, Height: tt.height})
+		if !reflect.DeepEqual(qualified, tt.qualified) {
+			t.Errorf("%dx%d qualified for %v, want %v", tt.width, tt.height, qualified, tt.qualified)
+		}
+		if len(missed) != len(tt.missed) {
+			t.Fatalf("%dx%d missed %v, want %v", tt.width, tt.height, missed, tt.missed)
+		}
+		for i, marketplace := range tt.missed {
+			if !strings.Contains(missed[i], marketplace) {
+				t.Errorf("%dx%d reason %q does not name %s", tt.width, tt.height, missed[i], marketplace)
+			}
+		}
+	}
+}
+
+func encodePNG(t *testing.T, width, height int) []byte {
+	t.Helper()
+	img := image.NewNRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		img.Set(x, x%height, color.Black)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader builds a PNG that declares its dimensions but carries no pixels
+func pngHeader(width, height uint32) []byte {
+	ihdr := make([]byte, 13)
+	binary.BigEndian.PutUint32(ihdr[0:4], width)
+	binary.BigEndian.PutUint32(ihdr[4:8], height)
+	ihdr[8], ihdr[9] = 8, 6 // 8-bit RGBA
+
+	buf := []byte("\x89PNG\r\n\x1a\n")
+	buf = binary.BigEndian.AppendUint32(buf, uint32(len(ihdr)))
+	chunk := append([]byte("IHDR"), ihdr...)
+	buf = append(buf, chunk...)
+	return binary.BigEndian.AppendUint32(buf, crc32.ChecksumIEEE(chunk))
+}
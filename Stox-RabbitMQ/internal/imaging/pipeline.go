@@ -0,0 +1,287 @@
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"math"
+	"sync"
+)
+
+// Step transforms an image as part of enhancement
+type Step func(img *image.NRGBA) (*image.NRGBA, error)
+
+var (
+	hookMu           sync.RWMutex
+	watermarkRemover Step
+)
+
+// RegisterWatermarkRemover installs a watermark removal step that runs before
+// any other enhancement. There is no built-in remover; plug in a model-backed one.
+func RegisterWatermarkRemover(step Step) {
+	hookMu.Lock()
+	defer hookMu.Unlock()
+	watermarkRemover = step
+}
+
+// Rendition is an output size derived from the enhanced image.
+// The image is fitted inside Width x Height and padded on white to exactly that size.
+type Rendition struct {
+	Name   string
+	Width  int
+	Height int
+}
+
+// Renditions are produced for every enhanced image
+var Renditions = []Rendition{
+	{Name: "amazon", Width: 2000, Height: 2000},      // square, white background for main images
+	{Name: "trendyol", Width: 1200, Height: 1800},    // 2:3 portrait
+	{Name: "hepsiburada", Width: 1500, Height: 1500}, // square
+	{Name: "thumbnail", Width: 300, Height: 300},
+}
+
+// Decode decodes a JPEG, PNG or GIF image. WebP can be inspected but not decoded.
+func Decode(data []byte) (image.Image, error) {
+	if isWebP(data) {
+		return nil, fmt.Errorf("%w: WEBP decoding is not available", ErrUnsupportedFormat)
+	}
+	if _, err := decodeConfig(data); err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+	return img, nil
+}
+
+// Enhance flattens an image onto white, runs the watermark hook and stretches
+// its contrast. It returns the enhanced image and the names of the applied steps.
+func Enhance(src image.Image) (*image.NRGBA, []string, error) {
+	img := Flatten(src, color.White)
+	steps := []string{}
+
+	hookMu.RLock()
+	remover := watermarkRemover
+	hookMu.RUnlock()
+
+	if remover != nil {
+		var err error
+		img, err = remover(img)
+		if err != nil {
+			return nil, nil, fmt.Errorf("watermark removal failed: %w", err)
+		}
+		steps = append(steps, "watermark_removal")
+	}
+
+	img = AutoContrast(img, 0.005)
+	steps = append(steps, "auto_contrast")
+
+	return img, steps, nil
+}
+
+// Render produces a rendition from an enhanced image
+func Render(img *image.NRGBA, r Rendition) *image.NRGBA {
+	return Pad(Fit(img, r.Width, r.Height), r.Width, r.Height, color.White)
+}
+
+// EncodeJPEG encodes an image as JPEG
+func EncodeJPEG(img image.Image, quality int) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+		return nil, fmt.Errorf("failed to encode JPEG: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// Flatten draws an image over a solid background, removing transparency
+func Flatten(src image.Image, bg color.Color) *image.NRGBA {
+	b := src.Bounds()
+	dst := image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+	draw.Draw(dst, dst.Bounds(), src, b.Min, draw.Over)
+	return dst
+}
+
+// Fit scales an image up or down to the largest size inside maxW x maxH keeping its aspect ratio
+func Fit(img *image.NRGBA, maxW, maxH int) *image.NRGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	scale := math.Min(float64(maxW)/float64(w), float64(maxH)/float64(h))
+
+	newW := max(1, int(math.Round(float64(w)*scale)))
+	newH := max(1, int(math.Round(float64(h)*scale)))
+	return Resize(img, newW, newH)
+}
+
+// Pad centers an image on a width x height canvas filled with bg
+func Pad(img *image.NRGBA, width, height int, bg color.Color) *image.NRGBA {
+	b := img.Bounds()
+	if b.Dx() == width && b.Dy() == height {
+		return img
+	}
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(dst, dst.Bounds(), image.NewUniform(bg), image.Point{}, draw.Src)
+
+	offset := image.Pt((width-b.Dx())/2, (height-b.Dy())/2)
+	draw.Draw(dst, b.Sub(b.Min).Add(offset), img, b.Min, draw.Over)
+	return dst
+}
+
+// PadSquare pads an image to a square whose side is its longest edge
+func PadSquare(img *image.NRGBA, bg color.Color) *image.NRGBA {
+	side := max(img.Bounds().Dx(), img.Bounds().Dy())
+	return Pad(img, side, side, bg)
+}
+
+// Resize scales an image to exactly width x height with a triangle filter,
+// which is bilinear when upscaling and area-averaging when downscaling
+func Resize(img *image.NRGBA, width, height int) *image.NRGBA {
+	b := img.Bounds()
+	if b.Dx() == width && b.Dy() == height {
+		return img
+	}
+
+	src := img
+	if b.Min != (image.Point{}) { // the resamplers index Pix from the origin
+		src = image.NewNRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+		draw.Draw(src, src.Bounds(), img, b.Min, draw.Src)
+	}
+	tmp := image.NewNRGBA(image.Rect(0, 0, width, b.Dy()))
+	resampleRows(src, tmp, resampleWeights(b.Dx(), width))
+
+	dst := image.NewNRGBA(image.Rect(0, 0, width, height))
+	resampleCols(tmp, dst, resampleWeights(b.Dy(), height))
+	return dst
+}
+
+// contribution is the weighted set of source pixels for one output pixel
+type contribution struct {
+	start   int
+	weights []float64
+}
+
+func resampleWeights(srcSize, dstSize int) []contribution {
+	scale := float64(srcSize) / float64(dstSize)
+	support := math.Max(scale, 1)
+
+	out := make([]contribution, dstSize)
+	for i := range out {
+		center := (float64(i)+0.5)*scale - 0.5
+		lo := int(math.Ceil(center - support))
+		hi := int(math.Floor(center + support))
+		lo, hi = max(lo, 0), min(hi, srcSize-1)
+
+		weights := make([]float64, 0, hi-lo+1)
+		total := 0.0
+		for j := lo; j <= hi; j++ {
+			w := 1 - math.Abs(float64(j)-center)/support
+			if w < 0 {
+				w = 0
+			}
+			weights = append(weights, w)
+			total += w
+		}
+		if total == 0 { // degenerate 1px source
+			weights[len(weights)/2], total = 1, 1
+		}
+		for k := range weights {
+			weights[k] /= total
+		}
+		out[i] = contribution{start: lo, weights: weights}
+	}
+	return out
+}
+
+func resampleRows(src, dst *image.NRGBA, contribs []contribution) {
+	for y := 0; y < dst.Rect.Dy(); y++ {
+		srcRow := src.Pix[y*src.Stride:]
+		dstRow := dst.Pix[y*dst.Stride:]
+		for x, c := range contribs {
+			var acc [4]float64
+			for k, w := range c.weights {
+				p := srcRow[(c.start+k)*4:]
+				acc[0] += float64(p[0]) * w
+				acc[1] += float64(p[1]) * w
+				acc[2] += float64(p[2]) * w
+				acc[3] += float64(p[3]) * w
+			}
+			storePixel(dstRow[x*4:], acc)
+		}
+	}
+}
+
+func resampleCols(src, dst *image.NRGBA, contribs []contribution) {
+	for y, c := range contribs {
+		dstRow := dst.Pix[y*dst.Stride:]
+		for x := 0; x < dst.Rect.Dx(); x++ {
+			var acc [4]float64
+			for k, w := range c.weights {
+				p := src.Pix[(c.start+k)*src.Stride+x*4:]
+				acc[0] += float64(p[0]) * w
+				acc[1] += float64(p[1]) * w
+				acc[2] += float64(p[2]) * w
+				acc[3] += float64(p[3]) * w
+			}
+			storePixel(dstRow[x*4:], acc)
+		}
+	}
+}
+
+func storePixel(p []uint8, acc [4]float64) {
+	for i, v := range acc {
+		p[i] = uint8(math.Max(0, math.Min(255, math.Round(v))))
+	}
+}
+
+// AutoContrast stretches the luminance range so that the darkest and brightest
+// clip fraction of pixels map to black and white
+func AutoContrast(img *image.NRGBA, clip float64) *image.NRGBA {
+	var hist [256]int
+	w, h := img.Rect.Dx(), img.Rect.Dy()
+	for y := 0; y < h; y++ {
+		row := img.Pix[y*img.Stride:]
+		for x := 0; x < w; x++ {
+			p := row[x*4:]
+			hist[luminance(p[0], p[1], p[2])]++
+		}
+	}
+
+	cut := int(float64(w*h) * clip)
+	low, high := 0, 255
+	for sum := 0; low < 255; low++ {
+		if sum += hist[low]; sum > cut {
+			break
+		}
+	}
+	for sum := 0; high > 0; high-- {
+		if sum += hist[high]; sum > cut {
+			break
+		}
+	}
+	if high-low < 2 || (low == 0 && high == 255) {
+		return img // flat or already full range
+	}
+
+	var lut [256]uint8
+	for v := range lut {
+		stretched := float64(v-low) * 255 / float64(high-low)
+		lut[v] = uint8(math.Max(0, math.Min(255, math.Round(stretched))))
+	}
+
+	dst := image.NewNRGBA(img.Rect)
+	for i := 0; i < len(img.Pix); i += 4 {
+		dst.Pix[i] = lut[img.Pix[i]]
+		dst.Pix[i+1] = lut[img.Pix[i+1]]
+		dst.Pix[i+2] = lut[img.Pix[i+2]]
+		dst.Pix[i+3] = img.Pix[i+3]
+	}
+	return dst
+}
+
+func luminance(r, g, b uint8) uint8 {
+	return uint8((299*int(r) + 587*int(g) + 114*int(b)) / 1000)
+}
@@ -0,0 +1,131 @@
+// Package listing validates listing content against marketplace rules before
+// marketplace services create listings.
+package listing
+
+import (
+	"fmt"
+
+	"stox-rabbitmq/internal/imaging"
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/seo"
+	"stox-rabbitmq/internal/taxonomy"
+)
+
+// ReviewQueue receives products whose listings need manual review.
+// It is bound to stox.sync with the queue name as routing key.
+const ReviewQueue = "listing.needs_review"
+
+// Listing is the content a marketplace service is about to list
+type Listing struct {
+	Marketplace string
+	Locale      string
+	Title       string
+	Description string
+	Attributes  map[string]string
+	Images      []models.Image
+	Category    taxonomy.Mapping
+
+	VariantAttributes []string
+	Variants          []models.Variant
+}
+
+// NewListing picks a product's content in the marketplace's locale and the
+// marketplace category it was mapped to
+func NewListing(marketplace string, product models.Product, category taxonomy.Mapping) Listing {
+	locale := seo.MarketplaceLocale(marketplace)
+	content := product.SEOFor(locale)
+	if content.Title == "" {
+		content.Title = product.Title
+	}
+	if content.Description == "" {
+		content.Description = product.Description
+	}
+
+	return Listing{
+		Marketplace: marketplace,
+		Locale:      locale,
+		Title:       content.Title,
+		Description: content.Description,
+		Attributes:  product.Attributes,
+		Images:      append([]models.Image(nil), product.Images...),
+		Category:    category,
+
+		VariantAttributes: product.VariantAttributes,
+		Variants:          append([]models.Variant(nil), product.Variants...),
+	}
+}
+
+// Policy holds one marketplace's listing requirements
+type Policy struct {
+	Marketplace        string
+	TitleMax           int // characters
+	DescriptionMax     int // characters
+	ForbiddenWords     []string
+	RequiredAttributes []string // on top of those the listing's category requires
+	MinImages          int
+	MaxImages          int
+	MinResolution      imaging.Resolution // originals without a marketplace rendition
+	MaxImageBytes      int64
+	Variants           VariantModel
+}
+
+// Policies are the listing requirements of each marketplace
+var Policies = map[string]Policy{
+	"amazon": {
+		Marketplace:        "amazon",
+		ForbiddenWords:     []string{"free shipping", "best seller", "best price", "guaranteed", "100% quality", "eco-friendly"},
+		RequiredAttributes: []string{"brand"},
+		MinImages:          1,
+		MaxImages:          9,
+		MaxImageBytes:      10 << 20,
+		Variants: VariantModel{
+			Attributes:  map[string]string{"color": "color_name", "size": "size_name", "style": "style_name"},
+			MaxVariants: 2000,
+			Themes:      true,
+		},
+	},
+	"trendyol": {
+		Marketplace:        "trendyol",
+		ForbiddenWords:     []string{"replika", "muadil", "ücretsiz kargo", "kargo bedava", "en ucuz"},
+		RequiredAttributes: []string{"brand", "color"},
+		MinImages:          1,
+		MaxImages:          8,
+		MaxImageBytes:      5 << 20,
+		Variants: VariantModel{
+			Attributes:  map[string]string{"color": "Renk", "size": "Beden"},
+			MaxVariants: 100,
+		},
+	},
+	"hepsiburada": {
+		Marketplace:        "hepsiburada",
+		ForbiddenWords:     []string{"replika", "muadil", "kargo bedava", "en ucuz", "fiyat garantisi"},
+		RequiredAttributes: []string{"brand"},
+		MinImages:          1,
+		MaxImages:          10,
+		MaxImageBytes:      5 << 20,
+		Variants: VariantModel{
+			Attributes:  map[string]string{"color": "Renk", "size": "Beden", "capacity": "Kapasite"},
+			MaxVariants: 200,
+		},
+	},
+}
+
+// PolicyFor returns a marketplace's policy with the content limits from
+// seo.Targets and the image minimum from imaging.MarketplaceMinimums
+func PolicyFor(marketplace string) (Policy, error) {
+	policy, ok := Policies[marketplace]
+	if !ok {
+		return Policy{}, fmt.Errorf("no listing policy for marketplace %q", marketplace)
+	}
+
+	limits := seo.DefaultLimits
+	for _, t := range seo.Targets {
+		if t.Marketplace == marketplace {
+			limits = t.Limits
+		}
+	}
+	policy.TitleMax = limits.TitleMax
+	policy.DescriptionMax = limits.DescriptionMax
+	policy.MinResolution = imaging.MarketplaceMinimums[marketplace]
+	return policy, nil
+}
@@ -0,0 +1,260 @@
+package listing
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/seo"
+	"stox-rabbitmq/internal/taxonomy"
+)
+
+// Rule checks one listing requirement. Fix is nil for rules that need a human;
+// otherwise it corrects what it can, and anything the check still finds goes to review.
+type Rule struct {
+	ID    string
+	Check func(p Policy, l Listing) []models.ListingViolation
+	Fix   func(p Policy, l *Listing)
+}
+
+// Rules run for every marketplace, parameterized by its policy
+var Rules = slices.Concat(contentRules, variantRules)
+
+// contentRules check the listing's text, attributes, category and images
+var contentRules = []Rule{
+	{
+		ID: "title_length",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			if n := utf8.RuneCountInString(l.Title); n > p.TitleMax {
+				return violation("title", "title is %d characters, limit is %d", n, p.TitleMax)
+			}
+			return nil
+		},
+		Fix: func(p Policy, l *Listing) { l.Title = seo.Truncate(l.Title, p.TitleMax) },
+	},
+	{
+		ID: "description_length",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			if n := utf8.RuneCountInString(l.Description); n > p.DescriptionMax {
+				return violation("description", "description is %d characters, limit is %d", n, p.DescriptionMax)
+			}
+			return nil
+		},
+		Fix: func(p Policy, l *Listing) { l.Description = seo.Truncate(l.Description, p.DescriptionMax) },
+	},
+	{
+		ID: "forbidden_words",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			var violations []models.ListingViolation
+			for _, field := range []struct{ name, text string }{{"title", l.Title}, {"description", l.Description}} {
+				text := seo.Lower(l.Locale, field.text)
+				for _, word := range p.ForbiddenWords {
+					if seo.ContainsPhrase(text, word) {
+						violations = append(violations, violation(field.name, "%s contains forbidden word %q", field.name, word)...)
+					}
+				}
+			}
+			return violations
+		},
+	},
+	{
+		ID: "category_mapping",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			if l.Category.Mapped() {
+				return nil
+			}
+			if len(l.Category.Suggestions) == 0 {
+				return violation("category", "no %s category for %q", p.Marketplace, l.Category.Input)
+			}
+			return violation("category", "no %s category for %q, suggestions: %s",
+				p.Marketplace, l.Category.Input, taxonomy.SuggestionList(l.Category.Suggestions))
+		},
+	},
+	{
+		ID: "required_attributes",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			var violations []models.ListingViolation
+			for _, name := range requiredAttributes(p, l) {
+				if slices.Contains(l.VariantAttributes, name) {
+					continue // set per variant, checked by variant_values
+				}
+				if strings.TrimSpace(l.Attributes[name]) == "" {
+					violations = append(violations, violation("attributes."+name, "attribute %q is required", name)...)
+				}
+			}
+			return violations
+		},
+	},
+	{
+		ID: "image_resolution",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			var violations []models.ListingViolation
+			for _, img := range l.Images {
+				if tooSmall(p, img) {
+					violations = append(violations, violation("images", "image %s is %dx%d, minimum is %dx%d",
+						img.ID, img.Width, img.Height, p.MinResolution.Width, p.MinResolution.Height)...)
+				}
+			}
+			return violations
+		},
+		Fix: func(p Policy, l *Listing) {
+			l.Images = dropImages(l.Images, p.MinImages, func(img models.Image) bool { return tooSmall(p, img) })
+		},
+	},
+	{
+		ID: "image_file_size",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			var violations []models.ListingViolation
+			for _, img := range l.Images {
+				if tooLarge(p, img) {
+					violations = append(violations, violation("images", "image %s is %d bytes, limit is %d",
+						img.ID, img.Size, p.MaxImageBytes)...)
+				}
+			}
+			return violations
+		},
+		Fix: func(p Policy, l *Listing) {
+			l.Images = dropImages(l.Images, p.MinImages, func(img models.Image) bool { return tooLarge(p, img) })
+		},
+	},
+	{
+		ID: "max_images",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			if len(l.Images) > p.MaxImages {
+				return violation("images", "%d images, limit is %d", len(l.Images), p.MaxImages)
+			}
+			return nil
+		},
+		Fix: func(p Policy, l *Listing) { l.Images = l.Images[:p.MaxImages] },
+	},
+	{
+		ID: "min_images",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			if len(l.Images) < p.MinImages {
+				return violation("images", "%d images, at least %d required", len(l.Images), p.MinImages)
+			}
+			return nil
+		},
+	},
+}
+
+// Result is the outcome of validating a listing
+type Result struct {
+	Listing    Listing                   // with auto-fixes applied
+	Fixed      []models.ListingViolation // violations corrected automatically
+	Violations []models.ListingViolation // violations that need review
+}
+
+// OK reports whether the listing can be created
+func (r Result) OK() bool {
+	return len(r.Violations) == 0
+}
+
+// Validator checks listings for one marketplace
+type Validator struct {
+	policy Policy
+}
+
+// NewValidator creates the validator for a marketplace adapter
+func NewValidator(marketplace string) (*Validator, error) {
+	policy, err := PolicyFor(marketplace)
+	if err != nil {
+		return nil, err
+	}
+	return &Validator{policy: policy}, nil
+}
+
+// Policy returns the marketplace policy the validator enforces
+func (v *Validator) Policy() Policy {
+	return v.policy
+}
+
+// Validate runs every rule, auto-fixes what it can and reports what is left
+func (v *Validator) Validate(l Listing) Result {
+	result := Result{Listing: l}
+	for _, rule := range Rules {
+		violations := rule.Check(v.policy, result.Listing)
+		if len(violations) == 0 {
+			continue
+		}
+
+		if rule.Fix != nil {
+			rule.Fix(v.policy, &result.Listing)
+			remaining := rule.Check(v.policy, result.Listing)
+			result.Fixed = append(result.Fixed, tag(rule.ID, fixed(violations, remaining))...)
+			violations = remaining
+		}
+		result.Violations = append(result.Violations, tag(rule.ID, violations)...)
+	}
+	return result
+}
+
+func violation(field, format string, args ...interface{}) []models.ListingViolation {
+	return []models.ListingViolation{{Field: field, Message: fmt.Sprintf(format, args...)}}
+}
+
+func tag(rule string, violations []models.ListingViolation) []models.ListingViolation {
+	for i := range violations {
+		violations[i].Rule = rule
+	}
+	return violations
+}
+
+// fixed returns the violations a fix removed
+func fixed(before, after []models.ListingViolation) []models.ListingViolation {
+	left := make(map[string]bool, len(after))
+	for _, v := range after {
+		left[v.Message] = true
+	}
+
+	var out []models.ListingViolation
+	for _, v := range before {
+		if !left[v.Message] {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
+// requiredAttributes merges the marketplace-wide and category attributes
+func requiredAttributes(p Policy, l Listing) []string {
+	attrs := append([]string(nil), p.RequiredAttributes...)
+	for _, a := range l.Category.Attributes {
+		if !slices.Contains(attrs, a) {
+			attrs = append(attrs, a)
+		}
+	}
+	return attrs
+}
+
+// tooSmall reports originals below the marketplace minimum; images with a
+// rendition for the marketplace are resized to its spec by ai-service
+func tooSmall(p Policy, img models.Image) bool {
+	if _, ok := img.Renditions[p.Marketplace]; ok || img.Width == 0 {
+		return false
+	}
+	return img.Width < p.MinResolution.Width || img.Height < p.MinResolution.Height
+}
+
+func tooLarge(p Policy, img models.Image) bool {
+	if _, ok := img.Renditions[p.Marketplace]; ok {
+		return false
+	}
+	return p.MaxImageBytes > 0 && img.Size > p.MaxImageBytes
+}
+
+// dropImages removes images matching bad as long as at least keep images remain
+func dropImages(images []models.Image, keep int, bad func(models.Image) bool) []models.Image {
+	drops := len(images) - keep
+	var kept []models.Image
+	for _, img := range images {
+		if drops > 0 && bad(img) {
+			drops--
+			continue
+		}
+		kept = append(kept, img)
+	}
+	return kept
+}
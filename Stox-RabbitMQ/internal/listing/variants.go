@@ -0,0 +1,185 @@
+package listing
+
+import (
+	"fmt"
+	"strings"
+
+	"stox-rabbitmq/internal/models"
+)
+
+// VariantModel is how a marketplace groups a product's variants
+type VariantModel struct {
+	Attributes  map[string]string // Stox variant attribute -> marketplace attribute name
+	MaxVariants int
+	Themes      bool // Amazon-style variation themes named after the attributes
+}
+
+// Theme names the variation theme for a product's variant attributes, e.g.
+// SizeColor on Amazon; it is empty for marketplaces without themes
+func (m VariantModel) Theme(attributes []string) string {
+	if !m.Themes || len(attributes) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	for _, name := range attributes {
+		theme := strings.TrimSuffix(m.Attributes[name], "_name")
+		b.WriteString(strings.ToUpper(theme[:1]) + theme[1:])
+	}
+	return b.String()
+}
+
+// MapVariants converts a listing's variants to the marketplace's variant model.
+// price turns a variant into its marketplace price (markup, currency).
+// Listing IDs are left for the marketplace to assign.
+func MapVariants(p Policy, l Listing, price func(models.Variant) float64) (string, []models.ListingVariant) {
+	if len(l.Variants) == 0 {
+		return "", nil
+	}
+
+	urls := make(map[string]string, len(l.Images))
+	for _, img := range l.Images {
+		url := img.EnhancedURL
+		if url == "" {
+			url = img.OriginalURL
+		}
+		urls[img.ID] = url
+	}
+
+	variants := make([]models.ListingVariant, 0, len(l.Variants))
+	for _, v := range l.Variants {
+		attrs := make(map[string]string, len(l.VariantAttributes))
+		for _, name := range l.VariantAttributes {
+			attrs[p.Variants.Attributes[name]] = v.Attributes[name]
+		}
+
+		var images []string
+		for _, id := range v.ImageIDs {
+			if url := urls[id]; url != "" {
+				images = append(images, url)
+			}
+		}
+
+		variants = append(variants, models.ListingVariant{
+			SKU:        v.SKU,
+			Attributes: attrs,
+			Price:      price(v),
+			Stock:      v.Stock,
+			Images:     images,
+		})
+	}
+	return p.Variants.Theme(l.VariantAttributes), variants
+}
+
+// variantKey identifies a variant by its attribute values
+func variantKey(attributes []string, v models.Variant) string {
+	values := make([]string, len(attributes))
+	for i, name := range attributes {
+		values[i] = strings.ToLower(strings.TrimSpace(v.Attributes[name]))
+	}
+	return strings.Join(values, "/")
+}
+
+// variantRules check variants against the marketplace's variant model
+var variantRules = []Rule{
+	{
+		ID: "variant_attributes",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			var violations []models.ListingViolation
+			for _, name := range l.VariantAttributes {
+				if _, ok := p.Variants.Attributes[name]; !ok {
+					violations = append(violations, violation("variant_attributes",
+						"%s has no variant attribute for %q", p.Marketplace, name)...)
+				}
+			}
+			return violations
+		},
+	},
+	{
+		ID: "variant_values",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			var violations []models.ListingViolation
+			for i, v := range l.Variants {
+				if v.SKU == "" {
+					violations = append(violations, violation(fmt.Sprintf("variants[%d].sku", i), "variant %d has no SKU", i)...)
+				}
+				for _, name := range l.VariantAttributes {
+					if strings.TrimSpace(v.Attributes[name]) == "" {
+						violations = append(violations, violation(fmt.Sprintf("variants[%d].attributes.%s", i, name),
+							"variant %s has no %s", v.SKU, name)...)
+					}
+				}
+			}
+			return violations
+		},
+	},
+	{
+		ID: "variant_duplicates",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			var violations []models.ListingViolation
+			skus := make(map[string]bool)
+			combos := make(map[string]string)
+			for _, v := range l.Variants {
+				if v.SKU != "" && skus[v.SKU] {
+					violations = append(violations, violation("variants", "SKU %s is used by more than one variant", v.SKU)...)
+				}
+				skus[v.SKU] = true
+
+				key := variantKey(l.VariantAttributes, v)
+				if other, ok := combos[key]; ok && len(l.VariantAttributes) > 0 {
+					violations = append(violations, violation("variants", "variants %s and %s have the same %s",
+						other, v.SKU, strings.Join(l.VariantAttributes, "/"))...)
+				}
+				combos[key] = v.SKU
+			}
+			return violations
+		},
+	},
+	{
+		ID: "max_variants",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			if p.Variants.MaxVariants > 0 && len(l.Variants) > p.Variants.MaxVariants {
+				return violation("variants", "%d variants, limit is %d", len(l.Variants), p.Variants.MaxVariants)
+			}
+			return nil
+		},
+	},
+	{
+		ID: "variant_images",
+		Check: func(p Policy, l Listing) []models.ListingViolation {
+			known := make(map[string]bool, len(l.Images))
+			for _, img := range l.Images {
+				known[img.ID] = true
+			}
+
+			var violations []models.ListingViolation
+			for _, v := range l.Variants {
+				for _, id := range v.ImageIDs {
+					if !known[id] {
+						violations = append(violations, violation("variants", "variant %s refers to missing image %s", v.SKU, id)...)
+					}
+				}
+			}
+			return violations
+		},
+		// Images dropped by earlier fixes or image-service are unlinked
+		Fix: func(p Policy, l *Listing) {
+			known := make(map[string]bool, len(l.Images))
+			for _, img := range l.Images {
+				known[img.ID] = true
+			}
+
+			variants := make([]models.Variant, len(l.Variants))
+			for i, v := range l.Variants {
+				v.ImageIDs = nil
+				for _, id := range l.Variants[i].ImageIDs {
+					if known[id] {
+						v.ImageIDs = append(v.ImageIDs, id)
+					}
+				}
+				variants[i] = v
+			}
+			l.Variants = variants
+		},
+	},
+}
@@ -0,0 +1,153 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"log"
+	"sync"
+	"time"
+)
+
+// ErrCircuitOpen is returned without calling the API while its breaker is open
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// BreakerState is the state of a circuit breaker
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = iota // calls pass
+	BreakerOpen                         // calls fail fast until the cooldown ends
+	BreakerHalfOpen                     // one probe call decides whether to close
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "closed"
+}
+
+// Breaker opens after Threshold consecutive failed calls, so a marketplace
+// outage fails fast instead of burning retries. After Cooldown it lets one
+// probe call through: success closes it, failure opens it again.
+type Breaker struct {
+	mu        sync.Mutex
+	name      string
+	threshold int
+	cooldown  time.Duration
+	state     BreakerState
+	failures  int
+	probing   bool
+	changed   chan struct{} // closed on every state change
+	listeners []func(BreakerState)
+}
+
+// NewBreaker creates a closed breaker
+func NewBreaker(name string, threshold int, cooldown time.Duration) *Breaker {
+	if threshold < 1 {
+		threshold = 1
+	}
+	return &Breaker{name: name, threshold: threshold, cooldown: cooldown, changed: make(chan struct{})}
+}
+
+// State is the breaker's current state
+func (b *Breaker) State() BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// OnChange registers a function called (in its own goroutine) on every state change
+func (b *Breaker) OnChange(f func(BreakerState)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, f)
+}
+
+// Changed returns a channel closed at the next state change
+func (b *Breaker) Changed() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.changed
+}
+
+// Allow reports whether a call may be made; every allowed call must be followed by Record
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case BreakerOpen:
+		return ErrCircuitOpen
+	case BreakerHalfOpen:
+		if b.probing {
+			return ErrCircuitOpen
+		}
+		b.probing = true
+	}
+	return nil
+}
+
+// Record reports the outcome of an allowed call. A canceled call says nothing
+// about the API: it frees the probe slot and leaves the state as it is.
+func (b *Breaker) Record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if errors.Is(err, context.Canceled) {
+		b.probing = false
+		return
+	}
+
+	if !isFailure(err) {
+		b.failures = 0
+		b.probing = false
+		if b.state != BreakerClosed {
+			b.setState(BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == BreakerHalfOpen || (b.state == BreakerClosed && b.failures >= b.threshold) {
+		b.probing = false
+		b.setState(BreakerOpen)
+		log.Printf("🔌 %s circuit breaker open after %d failure(s): %v", b.name, b.failures, err)
+		time.AfterFunc(b.cooldown, b.halfOpen)
+	}
+}
+
+func (b *Breaker) halfOpen() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == BreakerOpen {
+		b.setState(BreakerHalfOpen)
+	}
+}
+
+// setState changes state and notifies listeners; callers hold b.mu
+func (b *Breaker) setState(state BreakerState) {
+	b.state = state
+	close(b.changed)
+	b.changed = make(chan struct{})
+	for _, f := range b.listeners {
+		go f(state)
+	}
+}
+
+// isFailure reports whether an error means the marketplace is unavailable,
+// as opposed to refusing one request
+func isFailure(err error) bool {
+	if err == nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	return true // network errors and timeouts
+}
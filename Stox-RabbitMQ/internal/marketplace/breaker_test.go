@@ -0,0 +1,108 @@
+package marketplace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensAndProbes(t *testing.T) {
+	b := NewBreaker("test", 3, 10*time.Millisecond)
+	outage := &APIError{Marketplace: "test", StatusCode: http.StatusServiceUnavailable}
+
+	// refused requests say nothing about availability
+	for i := 0; i < 5; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatal(err)
+		}
+		b.Record(&APIError{Marketplace: "test", StatusCode: http.StatusUnprocessableEntity})
+	}
+	if b.State() != BreakerClosed {
+		t.Fatalf("state %s after rejected requests, want closed", b.State())
+	}
+
+	for i := 0; i < 3; i++ {
+		if err := b.Allow(); err != nil {
+			t.Fatalf("call %d refused while closed: %v", i, err)
+		}
+		b.Record(outage)
+	}
+	if b.State() != BreakerOpen {
+		t.Fatalf("state %s after 3 failures, want open", b.State())
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("Allow while open = %v, want ErrCircuitOpen", err)
+	}
+
+	waitFor(t, b, BreakerHalfOpen)
+	if err := b.Allow(); err != nil {
+		t.Fatalf("probe refused: %v", err)
+	}
+	if err := b.Allow(); !errors.Is(err, ErrCircuitOpen) {
+		t.Fatalf("second call during the probe = %v, want ErrCircuitOpen", err)
+	}
+
+	// a failed probe opens it again, a successful one closes it
+	b.Record(fmt.Errorf("dial: %w", errors.New("connection refused")))
+	if b.State() != BreakerOpen {
+		t.Fatalf("state %s after a failed probe, want open", b.State())
+	}
+	waitFor(t, b, BreakerHalfOpen)
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Record(nil)
+	if b.State() != BreakerClosed {
+		t.Fatalf("state %s after a successful probe, want closed", b.State())
+	}
+}
+
+func TestBreakerIgnoresCanceledCalls(t *testing.T) {
+	b := NewBreaker("test", 1, 10*time.Millisecond)
+
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Record(fmt.Errorf("request: %w", context.Canceled))
+	if b.State() != BreakerClosed {
+		t.Fatalf("state %s after a canceled call, want closed", b.State())
+	}
+
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Record(errors.New("timeout"))
+	waitFor(t, b, BreakerHalfOpen)
+
+	// a canceled probe frees the slot for the next one without deciding anything
+	if err := b.Allow(); err != nil {
+		t.Fatal(err)
+	}
+	b.Record(context.Canceled)
+	if b.State() != BreakerHalfOpen {
+		t.Fatalf("state %s after a canceled probe, want half-open", b.State())
+	}
+	if err := b.Allow(); err != nil {
+		t.Fatalf("next probe refused after a canceled one: %v", err)
+	}
+}
+
+// waitFor waits until the breaker reaches state
+func waitFor(t *testing.T, b *Breaker, state BreakerState) {
+	t.Helper()
+	deadline := time.After(time.Second)
+	for {
+		changed := b.Changed()
+		if b.State() == state {
+			return
+		}
+		select {
+		case <-changed:
+		case <-deadline:
+			t.Fatalf("breaker still %s, want %s", b.State(), state)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	"stox-rabbitmq/internal/rabbitmq"
+)
+
+// BulkItem is the new stock and/or price of one SKU in a bulk update
+type BulkItem struct {
+	SKU   string   `json:"sku"`
+	Stock *int     `json:"stock,omitempty"`
+	Price *float64 `json:"price,omitempty"`
+}
+
+// BulkResult is the marketplace's answer for one bulk item
+type BulkResult struct {
+	SKU    string `json:"sku"`
+	Status string `json:"status"` // ok, failed, skipped
+	Error  string `json:"error,omitempty"`
+}
+
+// OK reports whether the item was applied
+func (r BulkResult) OK() bool { return r.Status == "ok" }
+
+// Skipped reports whether the item was discarded as stale without a call
+func (r BulkResult) Skipped() bool { return r.Status == "skipped" }
+
+// Wire types of the bulk endpoint
+type (
+	bulkRequest struct {
+		Items []BulkItem `json:"items"`
+	}
+	bulkResponse struct {
+		Results []BulkResult `json:"results"`
+	}
+)
+
+// BulkUpdate sets the stock and prices of many SKUs, in calls of at most
+// BulkLimits items. On error it returns the results of the calls made so far.
+func (c *Client) BulkUpdate(ctx context.Context, items []BulkItem) ([]BulkResult, error) {
+	size := BulkLimits[c.marketplace]
+	if size == 0 {
+		size = len(items)
+	}
+
+	var results []BulkResult
+	for start := 0; start < len(items); start += size {
+		end := min(start+size, len(items))
+
+		var resp bulkResponse
+		err := c.do(ctx, EndpointBulk, http.MethodPost, "/bulk", bulkRequest{Items: items[start:end]}, &resp)
+		if err != nil {
+			return results, err
+		}
+		results = append(results, resp.Results...)
+	}
+	return results, nil
+}
+
+// coalesced is a bulk item with the changes whose stock and price it carries
+type coalesced struct {
+	item             BulkItem
+	stockAt, priceAt int // change indexes, -1 for none
+}
+
+// Coalesce merges the changes addressed to a marketplace into one bulk item
+// per SKU, keeping the latest stock and the latest price separately (by
+// Position, ties going to the later change). index maps every change to its
+// item, or -1 when it is not for this marketplace.
+func Coalesce(marketplace string, changes []Change) (items []BulkItem, index []int) {
+	merged, index := coalesce(marketplace, changes)
+	items = make([]BulkItem, len(merged))
+	for n, m := range merged {
+		items[n] = m.item
+	}
+	return items, index
+}
+
+func coalesce(marketplace string, changes []Change) ([]*coalesced, []int) {
+	var merged []*coalesced
+	bySKU := make(map[string]int)
+	index := make([]int, len(changes))
+
+	newer := func(at, i int) bool {
+		return at < 0 || changes[i].Position >= changes[at].Position
+	}
+
+	for i, change := range changes {
+		if change.Marketplace != marketplace && change.Marketplace != "all" {
+			index[i] = -1
+			continue
+		}
+
+		n, ok := bySKU[change.SKU]
+		if !ok {
+			n = len(merged)
+			bySKU[change.SKU] = n
+			merged = append(merged, &coalesced{item: BulkItem{SKU: change.SKU}, stockAt: -1, priceAt: -1})
+		}
+		index[i] = n
+
+		m := merged[n]
+		if change.Stock != nil && newer(m.stockAt, i) {
+			stock := *change.Stock
+			m.item.Stock, m.stockAt = &stock, i
+		}
+		if change.Price != nil && newer(m.priceAt, i) {
+			price := *change.Price
+			m.item.Price, m.priceAt = &price, i
+		}
+	}
+	return merged, index
+}
+
+// SyncBatch applies stock and price changes with as few bulk calls as
+// possible; a change only sets the fields it carries. With a Sequencer,
+// stock and prices older than those already applied to a SKU are discarded
+// and reported as skipped. convert turns prices into the marketplace's
+// currency (nil keeps them). It returns the per-SKU results and one error per
+// change, nil when its SKU was applied or skipped or the change is not for
+// this marketplace.
+func (c *Client) SyncBatch(ctx context.Context, changes []Change, convert func(float64) float64) ([]BulkResult, []error) {
+	merged, index := coalesce(c.marketplace, changes)
+	errs := make([]error, len(changes))
+
+	var items []BulkItem
+	var skipped []BulkResult
+	itemErrs := make([]error, len(merged))
+	for n, m := range merged {
+		if c.sequencer != nil {
+			applied, err := c.sequencer.Applied(ctx, m.item.SKU)
+			if err != nil {
+				// Without the last positions the changes cannot be ordered; retry them
+				itemErrs[n] = fmt.Errorf("%w: %v", rabbitmq.ErrRetryLater, err)
+				m.item.Stock, m.item.Price = nil, nil
+				continue
+			}
+			if m.stockAt >= 0 && stale(changes[m.stockAt].Position, applied.Stock) {
+				m.item.Stock, m.stockAt = nil, -1
+			}
+			if m.priceAt >= 0 && stale(changes[m.priceAt].Position, applied.Price) {
+				m.item.Price, m.priceAt = nil, -1
+			}
+		}
+		if m.item.Stock == nil && m.item.Price == nil {
+			skipped = append(skipped, BulkResult{SKU: m.item.SKU, Status: "skipped", Error: "older than the last applied change"})
+			continue
+		}
+
+		if convert != nil && m.item.Price != nil {
+			price := convert(*m.item.Price)
+			m.item.Price = &price
+		}
+		items = append(items, m.item)
+	}
+
+	var results []BulkResult
+	var callErr error
+	if len(items) > 0 {
+		results, callErr = c.BulkUpdate(ctx, items)
+	}
+	bySKU := make(map[string]BulkResult, len(results))
+	for _, result := range results {
+		bySKU[result.SKU] = result
+	}
+
+	applied := make(map[string]Applied)
+	for n, m := range merged {
+		if m.item.Stock == nil && m.item.Price == nil {
+			continue // skipped or failed above
+		}
+
+		result, ok := bySKU[m.item.SKU]
+		switch {
+		case !ok && callErr != nil:
+			itemErrs[n] = callErr
+		case !ok:
+			itemErrs[n] = fmt.Errorf("%s API: no result for %s", c.marketplace, m.item.SKU)
+		case !result.OK():
+			itemErrs[n] = fmt.Errorf("%s API: %s: %s", c.marketplace, m.item.SKU, result.Error)
+		default:
+			var a Applied
+			if m.stockAt >= 0 {
+				a.Stock = changes[m.stockAt].Position
+			}
+			if m.priceAt >= 0 {
+				a.Price = changes[m.priceAt].Position
+			}
+			applied[m.item.SKU] = a
+		}
+	}
+
+	if c.sequencer != nil && len(applied) > 0 {
+		if err := c.sequencer.Record(ctx, applied); err != nil {
+			log.Printf("⚠️  %s: %v", c.marketplace, err)
+		}
+	}
+
+	for i, n := range index {
+		if n >= 0 {
+			errs[i] = itemErrs[n]
+		}
+	}
+	return append(results, skipped...), errs
+}
+
+// stale reports whether a change at position is not newer than the applied
+// one; changes without a position are never stale
+func stale(position, applied int64) bool {
+	return position != 0 && position <= applied
+}
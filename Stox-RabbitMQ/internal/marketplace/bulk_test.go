@@ -0,0 +1,150 @@
+package marketplace
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestCoalesceKeepsLatestPerField(t *testing.T) {
+	changes := []Change{
+		{Marketplace: "amazon", SKU: "a", Stock: intPtr(5), Position: 3},
+		{Marketplace: "all", SKU: "a", Stock: intPtr(7), Position: 2}, // older than the first
+		{Marketplace: "trendyol", SKU: "a", Stock: intPtr(9), Position: 9},
+		{Marketplace: "amazon", SKU: "a", Price: floatPtr(10), Position: 1},
+		{Marketplace: "all", SKU: "b", Price: floatPtr(20), Position: 4},
+		{Marketplace: "amazon", SKU: "b", Price: floatPtr(21), Position: 4}, // tie: the later wins
+	}
+
+	items, index := Coalesce("amazon", changes)
+	if len(items) != 2 {
+		t.Fatalf("got %d items, want 2: %+v", len(items), items)
+	}
+	if a := items[0]; a.SKU != "a" || *a.Stock != 5 || *a.Price != 10 {
+		t.Errorf("item a = %s %d %v, want stock 5 and price 10", a.SKU, *a.Stock, *a.Price)
+	}
+	if b := items[1]; b.SKU != "b" || b.Stock != nil || *b.Price != 21 {
+		t.Errorf("item b = %+v, want only price 21", b)
+	}
+
+	want := []int{0, 0, -1, 0, 1, 1}
+	for i := range want {
+		if index[i] != want[i] {
+			t.Errorf("index = %v, want %v", index, want)
+			break
+		}
+	}
+}
+
+func TestSyncBatchSkipsStaleChanges(t *testing.T) {
+	ctx := context.Background()
+	api := newBulkAPI(t, "broken")
+	sequencer := NewSequencer(newTestStore(t), "amazon")
+	client, err := NewClient(ClientConfig{
+		Marketplace: "amazon",
+		URL:         api.server.URL,
+		Limits:      map[string]Limit{EndpointBulk: {}},
+		Sequencer:   sequencer,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sequencer.Record(ctx, map[string]Applied{"a": {Stock: 5, Price: 5}}); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []Change{
+		{Marketplace: "amazon", SKU: "a", Stock: intPtr(1), Position: 4},     // stale
+		{Marketplace: "amazon", SKU: "a", Price: floatPtr(10), Position: 6},  // newer
+		{Marketplace: "all", SKU: "b", Stock: intPtr(3), Price: floatPtr(2)}, // no position
+		{Marketplace: "amazon", SKU: "broken", Stock: intPtr(1), Position: 1},
+		{Marketplace: "hepsiburada", SKU: "c", Stock: intPtr(1), Position: 1},
+	}
+	double := func(price float64) float64 { return price * 2 }
+
+	results, errs := client.SyncBatch(ctx, changes, double)
+	if len(results) != 3 {
+		t.Fatalf("got %d results, want 3: %+v", len(results), results)
+	}
+	for i, err := range errs {
+		if (err != nil) != (i == 3) {
+			t.Errorf("change %d: error %v", i, err)
+		}
+	}
+
+	sent := api.items()
+	if len(sent) != 3 {
+		t.Fatalf("sent %+v, want a, b and broken", sent)
+	}
+	if a := sent[0]; a.SKU != "a" || a.Stock != nil || *a.Price != 20 {
+		t.Errorf("sent a as %+v, want only the converted price 20", a)
+	}
+	if b := sent[1]; b.SKU != "b" || *b.Stock != 3 || *b.Price != 4 {
+		t.Errorf("sent b as %+v, want stock 3 and price 4", b)
+	}
+
+	if applied, _ := sequencer.Applied(ctx, "a"); applied != (Applied{Stock: 5, Price: 6}) {
+		t.Errorf("applied positions of a = %+v, want stock 5 and price 6", applied)
+	}
+	if applied, _ := sequencer.Applied(ctx, "broken"); applied != (Applied{}) {
+		t.Errorf("a failed item was recorded as applied: %+v", applied)
+	}
+
+	// redelivering the whole batch applies nothing new
+	results, _ = client.SyncBatch(ctx, changes[:2], double)
+	if len(results) != 1 || !results[0].Skipped() {
+		t.Errorf("redelivered batch gave %+v, want a skipped", results)
+	}
+	if len(api.items()) != 3 {
+		t.Errorf("redelivered stale changes were sent again")
+	}
+}
+
+// bulkAPI answers bulk updates, failing the given SKUs, and records the items
+type bulkAPI struct {
+	server *httptest.Server
+	mu     sync.Mutex
+	sent   []BulkItem
+}
+
+func newBulkAPI(t *testing.T, failing ...string) *bulkAPI {
+	api := &bulkAPI{}
+	api.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req bulkRequest
+		if r.URL.Path != "/bulk" || json.NewDecoder(r.Body).Decode(&req) != nil {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		api.mu.Lock()
+		api.sent = append(api.sent, req.Items...)
+		api.mu.Unlock()
+
+		var resp bulkResponse
+		for _, item := range req.Items {
+			result := BulkResult{SKU: item.SKU, Status: "ok"}
+			for _, sku := range failing {
+				if item.SKU == sku {
+					result = BulkResult{SKU: item.SKU, Status: "failed", Error: "rejected"}
+				}
+			}
+			resp.Results = append(resp.Results, result)
+		}
+		json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(api.server.Close)
+	return api
+}
+
+func (api *bulkAPI) items() []BulkItem {
+	api.mu.Lock()
+	defer api.mu.Unlock()
+	return append([]BulkItem(nil), api.sent...)
+}
+
+func intPtr(n int) *int           { return &n }
+func floatPtr(f float64) *float64 { return &f }
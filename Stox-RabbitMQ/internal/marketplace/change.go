@@ -0,0 +1,125 @@
+package marketplace
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/rabbitmq"
+	"stox-rabbitmq/internal/schema"
+)
+
+// Change is a stock and/or price change for one SKU, as SyncBatch applies it.
+// Only the fields that are set are changed.
+type Change struct {
+	ProductID   string
+	Marketplace string // or "all"
+	SKU         string // variant SKU, or the product ID for whole products
+	Stock       *int
+	Price       *float64
+	Position    int64 // the change's version; 0 is applied unconditionally
+}
+
+// StockChangeOf is the change a StockChange makes
+func StockChangeOf(c models.StockChange) Change {
+	return Change{
+		ProductID:   c.ProductID,
+		Marketplace: c.Marketplace,
+		SKU:         skuOf(c.ProductID, c.SKU),
+		Stock:       c.Stock,
+		Position:    c.Version,
+	}
+}
+
+// PriceChangeOf is the change a PriceChange makes
+func PriceChangeOf(c models.PriceChange) Change {
+	return Change{
+		ProductID:   c.ProductID,
+		Marketplace: c.Marketplace,
+		SKU:         skuOf(c.ProductID, c.SKU),
+		Price:       c.Price,
+		Position:    c.Version,
+	}
+}
+
+// LegacySchema is the contract of sync messages sent without schema headers:
+// only legacy producers omit them, and those send inventory_update
+const LegacySchema = "inventory_update"
+
+// DecodeCommands reads a sync message by its schema header: a stock_change,
+// a price_change, or a legacy inventory_update (LegacySchema without a
+// header), which is split into the commands its update_type names. The
+// commands are validated.
+func DecodeCommands(d rabbitmq.Delivery) (*models.StockChange, *models.PriceChange, error) {
+	var stock *models.StockChange
+	var price *models.PriceChange
+
+	name, _ := d.Headers[schema.HeaderName].(string)
+	if name == "" {
+		name = LegacySchema
+	}
+	switch name {
+	case "stock_change":
+		stock = &models.StockChange{}
+		if err := json.Unmarshal(d.Body, stock); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal stock change: %w", err)
+		}
+	case "price_change":
+		price = &models.PriceChange{}
+		if err := json.Unmarshal(d.Body, price); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal price change: %w", err)
+		}
+	case "inventory_update":
+		var u models.InventoryUpdate
+		if err := json.Unmarshal(d.Body, &u); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal inventory update: %w", err)
+		}
+		stock, price = u.Commands()
+		if stock == nil && price == nil {
+			return nil, nil, fmt.Errorf("inventory_update: unknown update_type %q", u.UpdateType)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unexpected sync message %s", name)
+	}
+
+	if stock != nil {
+		if err := stock.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	if price != nil {
+		if err := price.Validate(); err != nil {
+			return nil, nil, err
+		}
+	}
+	return stock, price, nil
+}
+
+// DecodeChange reads a sync message as the change it makes (see DecodeCommands)
+func DecodeChange(d rabbitmq.Delivery) (Change, error) {
+	stock, price, err := DecodeCommands(d)
+	if err != nil {
+		return Change{}, err
+	}
+
+	var change Change
+	if stock != nil {
+		change = StockChangeOf(*stock)
+	}
+	if price != nil {
+		priceChange := PriceChangeOf(*price)
+		if stock == nil {
+			change = priceChange
+		}
+		change.Price = priceChange.Price
+	}
+	return change, nil
+}
+
+// skuOf addresses variants by SKU and whole products by their ID
+func skuOf(productID, sku string) string {
+	if sku == "" {
+		return productID
+	}
+	return sku
+}
@@ -0,0 +1,202 @@
+// Package marketplace talks to marketplace seller APIs for listings, stock and
+// prices. Mock serves the same protocol with configurable latency, failures,
+// rate limits and rejections for local development.
+package marketplace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"stox-rabbitmq/internal/models"
+)
+
+// APIError is a non-2xx answer from a marketplace API
+type APIError struct {
+	Marketplace string
+	StatusCode  int
+	Message     string
+	RetryAfter  time.Duration // from Retry-After on 429 and 503
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s API: %d %s: %s", e.Marketplace, e.StatusCode, http.StatusText(e.StatusCode), e.Message)
+}
+
+// Temporary reports whether the call may succeed when retried
+func (e *APIError) Temporary() bool {
+	return e.StatusCode == http.StatusTooManyRequests || e.StatusCode >= 500
+}
+
+// Rejected reports whether the marketplace refused the content itself
+func (e *APIError) Rejected() bool {
+	return e.StatusCode == http.StatusUnprocessableEntity
+}
+
+// Wire types shared by Client and Mock
+type (
+	// ListingResponse is the marketplace's answer to a new listing
+	ListingResponse struct {
+		ListingID string            `json:"listing_id"`
+		URL       string            `json:"url"`
+		Status    string            `json:"status"`
+		Variants  map[string]string `json:"variants,omitempty"` // SKU -> child listing ID
+	}
+	stockRequest struct {
+		Stock int `json:"stock"`
+	}
+	priceRequest struct {
+		Price float64 `json:"price"`
+	}
+	errorResponse struct {
+		Error string `json:"error"`
+	}
+)
+
+// ClientConfig points a client at a marketplace API
+type ClientConfig struct {
+	Marketplace string
+	URL         string           // base URL, e.g. http://localhost:9090/amazon
+	Limits      map[string]Limit // per endpoint, overriding DefaultLimits
+
+	// The circuit breaker opens after BreakerThreshold consecutive failures
+	// and probes again after BreakerCooldown
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
+
+	// Sequencer, when set, makes SyncBatch discard stale updates
+	Sequencer *Sequencer
+}
+
+// Client calls one marketplace's API, rate limited per endpoint and behind a circuit breaker
+type Client struct {
+	marketplace string
+	baseURL     string
+	client      *http.Client
+	limiters    map[string]*Limiter
+	breaker     *Breaker
+	sequencer   *Sequencer
+}
+
+// NewClient creates a client for a marketplace API
+func NewClient(config ClientConfig) (*Client, error) {
+	if config.URL == "" {
+		return nil, fmt.Errorf("%s API URL is not configured", config.Marketplace)
+	}
+	if config.BreakerThreshold == 0 {
+		config.BreakerThreshold = 5
+	}
+	if config.BreakerCooldown == 0 {
+		config.BreakerCooldown = 30 * time.Second
+	}
+
+	limiters := make(map[string]*Limiter)
+	for endpoint, limit := range DefaultLimits[config.Marketplace] {
+		limiters[endpoint] = NewLimiter(limit)
+	}
+	for endpoint, limit := range config.Limits {
+		limiters[endpoint] = NewLimiter(limit)
+	}
+
+	return &Client{
+		marketplace: config.Marketplace,
+		baseURL:     strings.TrimSuffix(config.URL, "/"),
+		client:      &http.Client{Timeout: 30 * time.Second},
+		limiters:    limiters,
+		breaker:     NewBreaker(config.Marketplace+" API", config.BreakerThreshold, config.BreakerCooldown),
+		sequencer:   config.Sequencer,
+	}, nil
+}
+
+// Marketplace is the marketplace the client calls
+func (c *Client) Marketplace() string { return c.marketplace }
+
+// Breaker is the client's circuit breaker
+func (c *Client) Breaker() *Breaker { return c.breaker }
+
+// CreateListing submits a listing; the marketplace assigns listing IDs and the URL
+func (c *Client) CreateListing(ctx context.Context, listing models.MarketplaceListing) (ListingResponse, error) {
+	var resp ListingResponse
+	err := c.do(ctx, EndpointListings, http.MethodPost, "/listings", listing, &resp)
+	return resp, err
+}
+
+// UpdateStock sets the stock of a listed product or variant SKU
+func (c *Client) UpdateStock(ctx context.Context, sku string, stock int) error {
+	return c.do(ctx, EndpointInventory, http.MethodPut, "/inventory/"+url.PathEscape(sku), stockRequest{Stock: stock}, nil)
+}
+
+// UpdatePrice sets the price of a listed product or variant SKU
+func (c *Client) UpdatePrice(ctx context.Context, sku string, price float64) error {
+	return c.do(ctx, EndpointPrices, http.MethodPut, "/prices/"+url.PathEscape(sku), priceRequest{Price: price}, nil)
+}
+
+// do calls an endpoint once its rate limit allows, unless the breaker is open
+func (c *Client) do(ctx context.Context, endpoint, method, path string, body, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	limiter := c.limiters[endpoint]
+	if limiter != nil {
+		if err := limiter.Wait(ctx); err != nil {
+			return err
+		}
+	}
+
+	if err := c.breaker.Allow(); err != nil {
+		return fmt.Errorf("%s API: %w", c.marketplace, err)
+	}
+	err = c.call(ctx, method, path, data, out)
+	c.breaker.Record(err)
+
+	var apiErr *APIError
+	if limiter != nil && errors.As(err, &apiErr) && apiErr.RetryAfter > 0 {
+		limiter.Backoff(apiErr.RetryAfter)
+	}
+	return err
+}
+
+func (c *Client) call(ctx context.Context, method, path string, data []byte, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s API: %w", c.marketplace, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		apiErr := &APIError{Marketplace: c.marketplace, StatusCode: resp.StatusCode, Message: resp.Status}
+		if s, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			apiErr.RetryAfter = time.Duration(s) * time.Second
+		}
+		var e errorResponse
+		raw, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if json.Unmarshal(raw, &e) == nil && e.Error != "" {
+			apiErr.Message = e.Error
+		}
+		return apiErr
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode %s API response: %w", c.marketplace, err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package marketplace
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Limit is a token bucket: Rate requests per second with bursts of up to Burst
+type Limit struct {
+	Rate  float64 `json:"rate"` // 0 for unlimited
+	Burst int     `json:"burst"`
+}
+
+// API endpoints limited separately, as the marketplaces do
+const (
+	EndpointListings  = "listings"
+	EndpointInventory = "inventory"
+	EndpointPrices    = "prices"
+	EndpointBulk      = "bulk"
+)
+
+// DefaultLimits keep each marketplace's endpoints together under its
+// documented quota (see DefaultProfiles for what the mock enforces)
+var DefaultLimits = map[string]map[string]Limit{
+	"amazon": {
+		EndpointListings:  {Rate: 2, Burst: 4},
+		EndpointInventory: {Rate: 2, Burst: 4},
+		EndpointPrices:    {Rate: 1, Burst: 2},
+		EndpointBulk:      {Rate: 0.5, Burst: 1},
+	},
+	"trendyol": {
+		EndpointListings:  {Rate: 3, Burst: 6},
+		EndpointInventory: {Rate: 5, Burst: 10},
+		EndpointPrices:    {Rate: 2, Burst: 4},
+		EndpointBulk:      {Rate: 1, Burst: 2},
+	},
+	"hepsiburada": {
+		EndpointListings:  {Rate: 1, Burst: 2},
+		EndpointInventory: {Rate: 1, Burst: 2},
+		EndpointPrices:    {Rate: 1, Burst: 1},
+		EndpointBulk:      {Rate: 0.5, Burst: 1},
+	},
+}
+
+// BulkLimits cap the items per bulk update call
+var BulkLimits = map[string]int{
+	"amazon":      500,
+	"trendyol":    1000,
+	"hepsiburada": 500,
+}
+
+// ParseLimits reads endpoint limits such as "inventory=2/4,prices=0.5"
+// (rate per second, optional burst defaulting to 1)
+func ParseLimits(s string) (map[string]Limit, error) {
+	limits := make(map[string]Limit)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		endpoint, value, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid rate limit %q: expected endpoint=rate[/burst]", part)
+		}
+		rate, burst, _ := strings.Cut(value, "/")
+
+		var limit Limit
+		var err error
+		if limit.Rate, err = strconv.ParseFloat(rate, 64); err != nil || limit.Rate < 0 {
+			return nil, fmt.Errorf("invalid rate in %q", part)
+		}
+		limit.Burst = 1
+		if burst != "" {
+			if limit.Burst, err = strconv.Atoi(burst); err != nil || limit.Burst < 1 {
+				return nil, fmt.Errorf("invalid burst in %q", part)
+			}
+		}
+		limits[strings.TrimSpace(endpoint)] = limit
+	}
+	return limits, nil
+}
+
+// Limiter is a token bucket rate limiter
+type Limiter struct {
+	mu     sync.Mutex
+	limit  Limit
+	tokens float64
+	last   time.Time
+	until  time.Time // no requests before this, e.g. after a 429 with Retry-After
+}
+
+// NewLimiter creates a limiter starting with a full bucket
+func NewLimiter(limit Limit) *Limiter {
+	return &Limiter{limit: limit, tokens: math.Max(float64(limit.Burst), 1)}
+}
+
+// take reports whether a request may proceed now, or how long until it could
+func (l *Limiter) take(now time.Time) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Before(l.until) {
+		return false, l.until.Sub(now)
+	}
+	if l.limit.Rate <= 0 {
+		return true, 0
+	}
+
+	capacity := math.Max(float64(l.limit.Burst), 1)
+	if !l.last.IsZero() {
+		l.tokens = math.Min(capacity, l.tokens+now.Sub(l.last).Seconds()*l.limit.Rate)
+	}
+	l.last = now
+	if l.tokens >= 1 {
+		l.tokens--
+		return true, 0
+	}
+	return false, time.Duration((1 - l.tokens) / l.limit.Rate * float64(time.Second))
+}
+
+// Wait blocks until a request may proceed or ctx is done
+func (l *Limiter) Wait(ctx context.Context) error {
+	for {
+		ok, wait := l.take(time.Now())
+		if ok {
+			return nil
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Backoff holds all requests for d, e.g. when the marketplace answers 429
+func (l *Limiter) Backoff(d time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if until := time.Now().Add(d); until.After(l.until) {
+		l.until = until
+	}
+}
@@ -0,0 +1,77 @@
+package marketplace
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestLimiterBurstThenRate(t *testing.T) {
+	l := NewLimiter(Limit{Rate: 2, Burst: 3})
+	now := time.Now()
+
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.take(now); !ok {
+			t.Fatalf("request %d of the burst refused", i+1)
+		}
+	}
+	ok, wait := l.take(now)
+	if ok {
+		t.Fatal("request beyond the burst allowed")
+	}
+	if wait != 500*time.Millisecond {
+		t.Errorf("wait %s, want 500ms at 2/s", wait)
+	}
+
+	// tokens refill at Rate, up to Burst
+	if ok, _ := l.take(now.Add(500 * time.Millisecond)); !ok {
+		t.Error("request refused after a token refilled")
+	}
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if ok, _ := l.take(later); !ok {
+			t.Fatalf("request %d refused after an idle hour", i+1)
+		}
+	}
+	if ok, _ := l.take(later); ok {
+		t.Error("idle time filled the bucket beyond Burst")
+	}
+}
+
+func TestLimiterUnlimitedAndBackoff(t *testing.T) {
+	l := NewLimiter(Limit{})
+	now := time.Now()
+	for i := 0; i < 100; i++ {
+		if ok, _ := l.take(now); !ok {
+			t.Fatal("unlimited limiter refused a request")
+		}
+	}
+
+	l.Backoff(time.Minute)
+	ok, wait := l.take(time.Now())
+	if ok || wait <= 50*time.Second {
+		t.Errorf("take during backoff = %v, %s; want a wait of about a minute", ok, wait)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx); err != context.DeadlineExceeded {
+		t.Errorf("Wait during backoff = %v, want the context's error", err)
+	}
+}
+
+func TestParseLimits(t *testing.T) {
+	limits, err := ParseLimits(" inventory=2/4, prices=0.5 ,")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(limits) != 2 || limits[EndpointInventory] != (Limit{Rate: 2, Burst: 4}) || limits[EndpointPrices] != (Limit{Rate: 0.5, Burst: 1}) {
+		t.Errorf("parsed %v", limits)
+	}
+
+	for _, s := range []string{"inventory", "inventory=fast", "inventory=-1", "inventory=2/0"} {
+		if _, err := ParseLimits(s); err == nil {
+			t.Errorf("ParseLimits(%q) succeeded", s)
+		}
+	}
+}
@@ -0,0 +1,640 @@
+package marketplace
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/orders"
+)
+
+// maxCalls bounds the recorded calls kept for assertions
+const maxCalls = 5000
+
+// Profile is how a mock marketplace behaves
+type Profile struct {
+	LatencyMS  int     `json:"latency_ms"`  // added to every call
+	JitterMS   int     `json:"jitter_ms"`   // random extra latency up to this
+	ErrorRate  float64 `json:"error_rate"`  // fraction of calls answered with 500
+	RejectRate float64 `json:"reject_rate"` // fraction of valid listings rejected with 422
+	RateLimit  float64 `json:"rate_limit"`  // requests per second, 0 for unlimited; excess gets 429
+	Burst      int     `json:"burst"`
+
+	// WebhookURL receives new orders, signed with the mock's webhook secret ("" to only serve polling)
+	WebhookURL string `json:"webhook_url,omitempty"`
+}
+
+// DefaultProfiles roughly follow the real marketplaces' published limits
+var DefaultProfiles = map[string]Profile{
+	"amazon":      {LatencyMS: 150, JitterMS: 100, ErrorRate: 0.02, RateLimit: 5, Burst: 10},
+	"trendyol":    {LatencyMS: 100, JitterMS: 50, ErrorRate: 0.02, RateLimit: 10, Burst: 20},
+	"hepsiburada": {LatencyMS: 200, JitterMS: 150, ErrorRate: 0.02, RateLimit: 3, Burst: 5},
+}
+
+// Call is a recorded request to the mock
+type Call struct {
+	Time        time.Time       `json:"time"`
+	Marketplace string          `json:"marketplace"`
+	Method      string          `json:"method"`
+	Path        string          `json:"path"`
+	Status      int             `json:"status"`
+	Body        json.RawMessage `json:"body,omitempty"`
+}
+
+// MockListing is a listing or variant SKU held by the mock
+type MockListing struct {
+	SKU       string    `json:"sku"`
+	ProductID string    `json:"product_id"`
+	ListingID string    `json:"listing_id"`
+	Title     string    `json:"title"`
+	Price     float64   `json:"price"`
+	Stock     int       `json:"stock"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+type storedOrder struct {
+	createdAt time.Time
+	raw       json.RawMessage
+}
+
+// Mock emulates the listing, stock, price and order APIs of every marketplace
+// with an orders adapter, under /<marketplace>/...
+type Mock struct {
+	mu            sync.Mutex
+	profiles      map[string]Profile
+	limiters      map[string]*Limiter
+	listings      map[string]map[string]*MockListing // marketplace -> SKU
+	orders        map[string][]storedOrder
+	calls         []Call
+	rand          *rand.Rand
+	webhookSecret string
+}
+
+// NewMock creates a mock with the given profiles (DefaultProfiles for missing
+// marketplaces); seed makes injected failures reproducible
+func NewMock(profiles map[string]Profile, webhookSecret string, seed int64) *Mock {
+	m := &Mock{
+		profiles:      make(map[string]Profile),
+		limiters:      make(map[string]*Limiter),
+		listings:      make(map[string]map[string]*MockListing),
+		orders:        make(map[string][]storedOrder),
+		rand:          rand.New(rand.NewSource(seed)),
+		webhookSecret: webhookSecret,
+	}
+	for mp := range orders.Adapters {
+		profile, ok := profiles[mp]
+		if !ok {
+			profile = DefaultProfiles[mp]
+		}
+		m.profiles[mp] = profile
+		m.limiters[mp] = NewLimiter(Limit{Rate: profile.RateLimit, Burst: profile.Burst})
+		m.listings[mp] = make(map[string]*MockListing)
+	}
+	return m
+}
+
+// Handler serves the marketplace APIs and the /_calls and /_profiles admin endpoints
+func (m *Mock) Handler() http.Handler {
+	mux := http.NewServeMux()
+
+	for mp, adapter := range orders.Adapters {
+		mux.Handle("POST /"+mp+"/listings", m.simulate(mp, m.createListing))
+		mux.Handle("GET /"+mp+"/listings/{sku}", m.simulate(mp, m.getListing))
+		mux.Handle("PUT /"+mp+"/inventory/{sku}", m.simulate(mp, m.updateStock))
+		mux.Handle("PUT /"+mp+"/prices/{sku}", m.simulate(mp, m.updatePrice))
+		mux.Handle("POST /"+mp+"/bulk", m.simulate(mp, m.bulkUpdate))
+		mux.Handle("GET /"+mp+"/"+adapter.PollPath, m.simulate(mp, m.listOrders))
+		mux.HandleFunc("POST /"+mp+"/orders", func(w http.ResponseWriter, r *http.Request) {
+			m.injectOrder(mp, w, r)
+		})
+	}
+
+	mux.HandleFunc("GET /_calls", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, m.Calls(r.URL.Query().Get("marketplace")))
+	})
+	mux.HandleFunc("DELETE /_calls", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		m.calls = nil
+		m.mu.Unlock()
+		w.WriteHeader(http.StatusNoContent)
+	})
+	mux.HandleFunc("GET /_profiles", func(w http.ResponseWriter, r *http.Request) {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		writeJSON(w, http.StatusOK, m.profiles)
+	})
+	mux.HandleFunc("PUT /_profiles/{marketplace}", func(w http.ResponseWriter, r *http.Request) {
+		mp := r.PathValue("marketplace")
+		var profile Profile
+		if err := json.NewDecoder(r.Body).Decode(&profile); err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+			return
+		}
+
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		if _, ok := m.profiles[mp]; !ok {
+			writeJSON(w, http.StatusNotFound, errorResponse{Error: "unknown marketplace " + mp})
+			return
+		}
+		m.profiles[mp] = profile
+		m.limiters[mp] = NewLimiter(Limit{Rate: profile.RateLimit, Burst: profile.Burst})
+		log.Printf("⚙️  %s profile: %+v", mp, profile)
+		writeJSON(w, http.StatusOK, profile)
+	})
+
+	return mux
+}
+
+// Calls returns the recorded calls, optionally for one marketplace
+func (m *Mock) Calls(marketplace string) []Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	calls := make([]Call, 0, len(m.calls))
+	for _, c := range m.calls {
+		if marketplace == "" || c.Marketplace == marketplace {
+			calls = append(calls, c)
+		}
+	}
+	return calls
+}
+
+// statusRecorder captures the status written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+// simulate applies the marketplace's profile to an API handler and records the call
+func (m *Mock) simulate(mp string, handler func(mp string, w http.ResponseWriter, r *http.Request)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(io.LimitReader(r.Body, 1<<20))
+		r.Body = io.NopCloser(bytes.NewReader(body))
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		defer m.record(mp, r, body, rec)
+
+		m.mu.Lock()
+		profile := m.profiles[mp]
+		allowed, retryAfter := m.limiters[mp].take(time.Now())
+		delay := time.Duration(profile.LatencyMS) * time.Millisecond
+		if profile.JitterMS > 0 {
+			delay += time.Duration(m.rand.Intn(profile.JitterMS)) * time.Millisecond
+		}
+		fail := m.rand.Float64() < profile.ErrorRate
+		m.mu.Unlock()
+
+		if !allowed {
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			writeJSON(rec, http.StatusTooManyRequests, errorResponse{Error: "rate limit exceeded"})
+			return
+		}
+
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+
+		if fail {
+			writeJSON(rec, http.StatusInternalServerError, errorResponse{Error: "injected failure"})
+			return
+		}
+		handler(mp, rec, r)
+	})
+}
+
+func (m *Mock) record(mp string, r *http.Request, body []byte, rec *statusRecorder) {
+	call := Call{
+		Time:        time.Now(),
+		Marketplace: mp,
+		Method:      r.Method,
+		Path:        r.URL.RequestURI(),
+		Status:      rec.status,
+	}
+	if json.Valid(body) {
+		call.Body = body
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = append(m.calls, call)
+	if len(m.calls) > maxCalls {
+		m.calls = m.calls[len(m.calls)-maxCalls:]
+	}
+}
+
+// rejectionReasons are picked for randomly rejected listings
+var rejectionReasons = []string{
+	"listing rejected by content review: image shows a watermark",
+	"listing rejected by content review: brand is not authorized for this seller",
+	"listing rejected by content review: category does not match the product",
+}
+
+func (m *Mock) createListing(mp string, w http.ResponseWriter, r *http.Request) {
+	var listing models.MarketplaceListing
+	if err := json.NewDecoder(r.Body).Decode(&listing); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	switch {
+	case listing.ProductID == "":
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "product_id is required"})
+		return
+	case listing.Title == "":
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "title is required"})
+		return
+	case listing.Price <= 0 && len(listing.Variants) == 0:
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "price must be positive"})
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.rand.Float64() < m.profiles[mp].RejectRate {
+		reason := rejectionReasons[m.rand.Intn(len(rejectionReasons))]
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: reason})
+		return
+	}
+
+	now := time.Now()
+	resp := ListingResponse{ListingID: m.listingID(mp), Status: "active"}
+	resp.URL = listingURL(mp, resp.ListingID)
+	m.listings[mp][listing.ProductID] = &MockListing{
+		SKU:       listing.ProductID,
+		ProductID: listing.ProductID,
+		ListingID: resp.ListingID,
+		Title:     listing.Title,
+		Price:     listing.Price,
+		Stock:     listing.Stock,
+		UpdatedAt: now,
+	}
+
+	if len(listing.Variants) > 0 {
+		resp.Variants = make(map[string]string, len(listing.Variants))
+	}
+	for _, v := range listing.Variants {
+		id := m.listingID(mp)
+		resp.Variants[v.SKU] = id
+		m.listings[mp][v.SKU] = &MockListing{
+			SKU:       v.SKU,
+			ProductID: listing.ProductID,
+			ListingID: id,
+			Title:     listing.Title,
+			Price:     v.Price,
+			Stock:     v.Stock,
+			UpdatedAt: now,
+		}
+	}
+
+	log.Printf("🛒 %s: listed %s as %s (%d variants)", mp, listing.ProductID, resp.ListingID, len(listing.Variants))
+	writeJSON(w, http.StatusCreated, resp)
+}
+
+func (m *Mock) getListing(mp string, w http.ResponseWriter, r *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	listing, ok := m.listings[mp][r.PathValue("sku")]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "unknown SKU " + r.PathValue("sku")})
+		return
+	}
+	writeJSON(w, http.StatusOK, listing)
+}
+
+func (m *Mock) updateStock(mp string, w http.ResponseWriter, r *http.Request) {
+	var req stockRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if req.Stock < 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "stock must not be negative"})
+		return
+	}
+
+	m.update(mp, w, r.PathValue("sku"), func(l *MockListing) {
+		log.Printf("📊 %s: stock of %s %d -> %d", mp, l.SKU, l.Stock, req.Stock)
+		l.Stock = req.Stock
+	})
+}
+
+func (m *Mock) updatePrice(mp string, w http.ResponseWriter, r *http.Request) {
+	var req priceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if req.Price <= 0 {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: "price must be positive"})
+		return
+	}
+
+	m.update(mp, w, r.PathValue("sku"), func(l *MockListing) {
+		log.Printf("💰 %s: price of %s %.2f -> %.2f", mp, l.SKU, l.Price, req.Price)
+		l.Price = req.Price
+	})
+}
+
+// bulkUpdate applies many stock and price changes, answering per item; a
+// profile's RejectRate also applies to each item
+func (m *Mock) bulkUpdate(mp string, w http.ResponseWriter, r *http.Request) {
+	var req bulkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+	if limit := BulkLimits[mp]; limit > 0 && len(req.Items) > limit {
+		writeJSON(w, http.StatusRequestEntityTooLarge, errorResponse{Error: fmt.Sprintf("at most %d items per call", limit)})
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	resp := bulkResponse{Results: make([]BulkResult, 0, len(req.Items))}
+	applied := 0
+	for _, item := range req.Items {
+		result := BulkResult{SKU: item.SKU, Status: "failed"}
+		listing, ok := m.listings[mp][item.SKU]
+		switch {
+		case !ok:
+			result.Error = "unknown SKU " + item.SKU
+		case item.Stock == nil && item.Price == nil:
+			result.Error = "stock or price is required"
+		case item.Stock != nil && *item.Stock < 0:
+			result.Error = "stock must not be negative"
+		case item.Price != nil && *item.Price <= 0:
+			result.Error = "price must be positive"
+		case m.rand.Float64() < m.profiles[mp].RejectRate:
+			result.Error = "item rejected by marketplace"
+		default:
+			if item.Stock != nil {
+				listing.Stock = *item.Stock
+			}
+			if item.Price != nil {
+				listing.Price = *item.Price
+			}
+			listing.UpdatedAt = time.Now()
+			result.Status, result.Error = "ok", ""
+			applied++
+		}
+		resp.Results = append(resp.Results, result)
+	}
+
+	log.Printf("📦 %s: bulk update applied %d/%d items", mp, applied, len(req.Items))
+	writeJSON(w, http.StatusOK, resp)
+}
+
+// update applies a change to a listed SKU
+func (m *Mock) update(mp string, w http.ResponseWriter, sku string, apply func(*MockListing)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	listing, ok := m.listings[mp][sku]
+	if !ok {
+		writeJSON(w, http.StatusNotFound, errorResponse{Error: "unknown SKU " + sku})
+		return
+	}
+	apply(listing)
+	listing.UpdatedAt = time.Now()
+	writeJSON(w, http.StatusOK, listing)
+}
+
+// listOrders serves orders created at or after ?since= in the marketplace's page format
+func (m *Mock) listOrders(mp string, w http.ResponseWriter, r *http.Request) {
+	var since time.Time
+	if s := r.URL.Query().Get("since"); s != "" {
+		t, err := time.Parse(time.RFC3339Nano, s)
+		if err != nil {
+			writeJSON(w, http.StatusBadRequest, errorResponse{Error: "invalid since: " + err.Error()})
+			return
+		}
+		since = t
+	}
+
+	m.mu.Lock()
+	var page []json.RawMessage
+	for _, o := range m.orders[mp] {
+		if !o.createdAt.Before(since) {
+			page = append(page, o.raw)
+		}
+	}
+	m.mu.Unlock()
+
+	body, err := orders.Adapters[mp].EncodePage(page)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, errorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(body)
+}
+
+// injectOrder adds an order, given as a models.Order, e.g. from a test
+func (m *Mock) injectOrder(mp string, w http.ResponseWriter, r *http.Request) {
+	var order models.Order
+	if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+		writeJSON(w, http.StatusBadRequest, errorResponse{Error: err.Error()})
+		return
+	}
+
+	raw, err := m.AddOrder(r.Context(), mp, order)
+	if err != nil {
+		writeJSON(w, http.StatusUnprocessableEntity, errorResponse{Error: err.Error()})
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(raw)
+}
+
+// marketplaceDefaults are the region and currency of generated orders
+var marketplaceDefaults = map[string]struct{ region, currency string }{
+	"amazon":      {"us", "USD"},
+	"trendyol":    {"tr", "TRY"},
+	"hepsiburada": {"tr", "TRY"},
+}
+
+// AddOrder stores a single-line order in the marketplace's format, serves it
+// to pollers and delivers it to the profile's webhook
+func (m *Mock) AddOrder(ctx context.Context, mp string, order models.Order) (json.RawMessage, error) {
+	adapter, err := orders.AdapterFor(mp)
+	if err != nil {
+		return nil, err
+	}
+	if order.SKU == "" || order.Quantity <= 0 {
+		return nil, fmt.Errorf("order needs a sku and a positive quantity")
+	}
+
+	defaults := marketplaceDefaults[mp]
+	if order.Region == "" {
+		order.Region = defaults.region
+	}
+	if order.Currency == "" {
+		order.Currency = defaults.currency
+	}
+	if order.Status == "" {
+		order.Status = "new"
+	}
+	if order.CreatedAt.IsZero() {
+		order.CreatedAt = time.Now().Truncate(time.Millisecond) // Trendyol dates have millisecond precision
+	}
+
+	m.mu.Lock()
+	if order.OrderID == "" {
+		order.OrderID = m.orderID(mp)
+	}
+	if listing, ok := m.listings[mp][order.SKU]; ok {
+		if order.Price == 0 {
+			order.Price = listing.Price
+		}
+		listing.Stock = max(listing.Stock-order.Quantity, 0)
+	}
+	webhookURL := m.profiles[mp].WebhookURL
+	m.mu.Unlock()
+
+	raw, err := adapter.Encode(order)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mu.Lock()
+	m.orders[mp] = append(m.orders[mp], storedOrder{createdAt: order.CreatedAt, raw: raw})
+	m.mu.Unlock()
+	log.Printf("📦 %s: order %s for %s x%d", mp, order.OrderID, order.SKU, order.Quantity)
+
+	if webhookURL != "" {
+		go func() {
+			err := orders.Deliver(context.WithoutCancel(ctx), webhookURL, m.webhookSecret, adapter, raw)
+			if err != nil {
+				log.Printf("⚠️  %s: webhook for order %s failed: %v", mp, order.OrderID, err)
+			}
+		}()
+	}
+	return raw, nil
+}
+
+// demoCustomers place generated orders
+var demoCustomers = map[string][]models.Customer{
+	"amazon": {
+		{Name: "John Smith", Email: "john.smith@email.com", Phone: "+1-555-0123",
+			Address: models.Address{Street: "123 Main St", City: "Seattle", State: "WA", Country: "US", ZipCode: "98101"}},
+		{Name: "Emily Clark", Email: "emily.clark@email.com", Phone: "+1-555-0188",
+			Address: models.Address{Street: "77 Market St", City: "San Francisco", State: "CA", Country: "US", ZipCode: "94103"}},
+	},
+	"trendyol": {
+		{Name: "Ahmet Yılmaz", Email: "ahmet.yilmaz@email.com", Phone: "+90-555-0123",
+			Address: models.Address{Street: "Bağdat Caddesi 123", City: "İstanbul", State: "Kadıköy", Country: "TR", ZipCode: "34740"}},
+	},
+	"hepsiburada": {
+		{Name: "Fatma Demir", Email: "fatma.demir@email.com", Phone: "+90-555-0456",
+			Address: models.Address{Street: "Halaskargazi Caddesi 456", City: "Ankara", State: "Çankaya", Country: "TR", ZipCode: "06230"}},
+	},
+}
+
+// GenerateOrders places a random order for a listed SKU on each marketplace
+// every interval until ctx is cancelled
+func (m *Mock) GenerateOrders(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		for mp := range orders.Adapters {
+			m.mu.Lock()
+			skus := make([]string, 0, len(m.listings[mp]))
+			for sku, l := range m.listings[mp] {
+				if l.Stock > 0 {
+					skus = append(skus, sku)
+				}
+			}
+			if len(skus) == 0 {
+				m.mu.Unlock()
+				continue
+			}
+			slices.Sort(skus)
+			sku := skus[m.rand.Intn(len(skus))]
+			customers := demoCustomers[mp]
+			customer := customers[m.rand.Intn(len(customers))]
+			quantity := 1 + m.rand.Intn(2)
+			m.mu.Unlock()
+
+			_, err := m.AddOrder(ctx, mp, models.Order{SKU: sku, Quantity: quantity, CustomerInfo: customer})
+			if err != nil {
+				log.Printf("⚠️  %s: failed to generate order: %v", mp, err)
+			}
+		}
+	}
+}
+
+// listingID generates a marketplace-style listing ID; callers hold m.mu
+func (m *Mock) listingID(mp string) string {
+	switch mp {
+	case "amazon":
+		const chars = "ABCDEFGHJKLMNPQRSTUVWXYZ0123456789"
+		id := []byte("B0")
+		for len(id) < 10 {
+			id = append(id, chars[m.rand.Intn(len(chars))])
+		}
+		return string(id)
+	case "hepsiburada":
+		return fmt.Sprintf("HBC%08d", m.rand.Intn(1e8))
+	default:
+		return strconv.Itoa(100000000 + m.rand.Intn(9e8))
+	}
+}
+
+// orderID generates a marketplace-style order ID; callers hold m.mu
+func (m *Mock) orderID(mp string) string {
+	switch mp {
+	case "amazon":
+		return fmt.Sprintf("%03d-%07d-%07d", m.rand.Intn(1000), m.rand.Intn(1e7), m.rand.Intn(1e7))
+	default:
+		return strconv.Itoa(1000000000 + m.rand.Intn(9e9))
+	}
+}
+
+func listingURL(mp, id string) string {
+	switch mp {
+	case "amazon":
+		return "https://www.amazon.com/dp/" + id
+	case "trendyol":
+		return "https://www.trendyol.com/stox/urun-p-" + id
+	case "hepsiburada":
+		return "https://www.hepsiburada.com/stox-p-" + id
+	}
+	return ""
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("Failed to write response: %v", err)
+	}
+}
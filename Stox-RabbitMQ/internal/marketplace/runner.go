@@ -0,0 +1,146 @@
+package marketplace
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	"stox-rabbitmq/internal/rabbitmq"
+)
+
+// probeWait bounds how long a message waits for the half-open probe before requeueing
+const probeWait = 10 * time.Second
+
+// Runner consumes the queues whose handlers call a marketplace API. While the
+// API's circuit breaker is open the consumers are paused, so messages wait in
+// their queues instead of failing; they resume when the breaker half-opens.
+type Runner struct {
+	api *Client
+
+	mu        sync.Mutex
+	consumers []*rabbitmq.Consumer
+}
+
+// NewRunner creates a runner for the marketplace API client
+func NewRunner(api *Client) *Runner {
+	r := &Runner{api: api}
+	api.Breaker().OnChange(func(BreakerState) { r.sync() })
+	return r
+}
+
+// Consume starts consuming a queue. Messages that fail because the marketplace
+// is unavailable or throttling are requeued rather than dead-lettered.
+func (r *Runner) Consume(client *rabbitmq.Client, queueName string, handler func(rabbitmq.Delivery) error) error {
+	consumer, err := client.StartConsumer(queueName, func(d rabbitmq.Delivery) error {
+		return r.guard(handler(d))
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.consumers = append(r.consumers, consumer)
+	r.mu.Unlock()
+
+	r.sync()
+	return nil
+}
+
+// ConsumeBatch starts consuming a queue in batches of up to size messages
+// collected within window; the handler returns one error per message
+func (r *Runner) ConsumeBatch(client *rabbitmq.Client, queueName string, size int, window time.Duration, handler func([]rabbitmq.Delivery) []error) error {
+	consumer, err := client.StartBatchConsumer(queueName, size, window, func(deliveries []rabbitmq.Delivery) []error {
+		// A failed bulk call is shared by its messages; guard it once
+		errs := handler(deliveries)
+		guarded := make(map[error]error)
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+			if _, ok := guarded[err]; !ok {
+				guarded[err] = r.guard(err)
+			}
+			errs[i] = guarded[err]
+		}
+		return errs
+	})
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	r.consumers = append(r.consumers, consumer)
+	r.mu.Unlock()
+
+	r.sync()
+	return nil
+}
+
+// Release stops consuming a queue for good, e.g. when another replica takes
+// over its partition, after the deliveries in hand are settled
+func (r *Runner) Release(queueName string) error {
+	r.mu.Lock()
+	var consumer *rabbitmq.Consumer
+	for i, c := range r.consumers {
+		if c.Queue() == queueName {
+			consumer = c
+			r.consumers = slices.Delete(r.consumers, i, i+1)
+			break
+		}
+	}
+	r.mu.Unlock()
+
+	if consumer == nil {
+		return nil
+	}
+	return consumer.Close()
+}
+
+// guard turns marketplace availability errors into requeues
+func (r *Runner) guard(err error) error {
+	if errors.Is(err, ErrCircuitOpen) {
+		// While another message probes the API, wait for its outcome rather
+		// than spinning through requeues
+		if r.api.Breaker().State() == BreakerHalfOpen {
+			select {
+			case <-r.api.Breaker().Changed():
+			case <-time.After(probeWait):
+			}
+		}
+		return fmt.Errorf("%w: %v", rabbitmq.ErrRetryLater, err)
+	}
+
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Temporary() {
+		return fmt.Errorf("%w: %v", rabbitmq.ErrRetryLater, err)
+	}
+	return err
+}
+
+// sync pauses or resumes the consumers to match the breaker
+func (r *Runner) sync() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	open := r.api.Breaker().State() == BreakerOpen
+	for _, consumer := range r.consumers {
+		if open == consumer.Paused() {
+			continue
+		}
+
+		var err error
+		if open {
+			err = consumer.Pause()
+			log.Printf("⏸️  %s API unavailable: paused %s", r.api.Marketplace(), consumer.Queue())
+		} else {
+			err = consumer.Resume()
+			log.Printf("▶️  %s API probing: resumed %s", r.api.Marketplace(), consumer.Queue())
+		}
+		if err != nil {
+			log.Printf("⚠️  %v", err)
+		}
+	}
+}
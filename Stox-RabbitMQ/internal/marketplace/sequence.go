@@ -0,0 +1,133 @@
+package marketplace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/storage"
+)
+
+// VersionClock stamps versions on changes where they are created, so their
+// order is the producer's and not the order they happen to be consumed in.
+// Each product's last version is kept in the store, so versions keep
+// increasing across restarts and stay ahead of models.VersionAt of the
+// current time. Versions stay below models.MaxVersion, so they survive codecs
+// that carry numbers as doubles (JSON, protobuf Value) exactly.
+//
+// The lock only covers one process: the store has no compare-and-swap, so
+// two replicas stamping the same product can hand out the same version and
+// one change is then discarded as already applied. Stamp a product's changes
+// from one process, or leave Version unset and let the timestamp order them.
+type VersionClock struct {
+	store storage.Store
+	mu    sync.Mutex // serializes read-modify-write of a product's counter
+}
+
+// NewVersionClock creates a version clock on the store
+func NewVersionClock(store storage.Store) *VersionClock {
+	return &VersionClock{store: store}
+}
+
+func (v *VersionClock) key(productID string) string {
+	return "sequences/versions/" + url.PathEscape(productID) + ".json"
+}
+
+// Next returns the product's next version: requested (typically
+// models.VersionAt(time.Now())) if it is ahead of the last one, otherwise the
+// version after the last one.
+func (v *VersionClock) Next(ctx context.Context, productID string, requested int64) (int64, error) {
+	if requested < 0 || requested > models.MaxVersion {
+		return 0, fmt.Errorf("version %d of %s is out of range", requested, productID)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	var last int64
+	data, err := v.store.Get(ctx, v.key(productID))
+	if err != nil && !errors.Is(err, storage.ErrNotFound) {
+		return 0, fmt.Errorf("failed to load version of %s: %w", productID, err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &last); err != nil {
+			return 0, fmt.Errorf("failed to decode version of %s: %w", productID, err)
+		}
+	}
+
+	version := max(last+1, requested)
+	if version > models.MaxVersion {
+		return 0, fmt.Errorf("versions of %s are exhausted", productID)
+	}
+	data, _ = json.Marshal(version)
+	if err := v.store.Put(ctx, v.key(productID), data); err != nil {
+		return 0, fmt.Errorf("failed to save version of %s: %w", productID, err)
+	}
+	return version, nil
+}
+
+// Applied is the position of the last stock and price applied to a SKU
+type Applied struct {
+	Stock int64 `json:"stock,omitempty"`
+	Price int64 `json:"price,omitempty"`
+}
+
+// Sequencer remembers the last stock and price applied per SKU, so that
+// updates overtaken by newer ones, or redelivered after them, are discarded
+// instead of overwriting newer values. Each SKU's positions are a blob in the
+// store, read on every batch: whichever replica owns a product's sync
+// partition sees what the previous owner applied.
+type Sequencer struct {
+	store  storage.Store
+	prefix string
+}
+
+// NewSequencer creates a marketplace's sequencer on the store
+func NewSequencer(store storage.Store, marketplace string) *Sequencer {
+	return &Sequencer{store: store, prefix: "sequences/inventory/" + marketplace + "/"}
+}
+
+func (s *Sequencer) key(sku string) string {
+	return s.prefix + url.PathEscape(sku) + ".json"
+}
+
+// Applied is the last stock and price applied to a SKU
+func (s *Sequencer) Applied(ctx context.Context, sku string) (Applied, error) {
+	var applied Applied
+	data, err := s.store.Get(ctx, s.key(sku))
+	if errors.Is(err, storage.ErrNotFound) {
+		return applied, nil
+	}
+	if err != nil {
+		return applied, fmt.Errorf("failed to load inventory sequence of %s: %w", sku, err)
+	}
+	if err := json.Unmarshal(data, &applied); err != nil {
+		return applied, fmt.Errorf("failed to decode inventory sequence of %s: %w", sku, err)
+	}
+	return applied, nil
+}
+
+// Record merges newly applied positions (0 leaves a field as it is) and saves them
+func (s *Sequencer) Record(ctx context.Context, applied map[string]Applied) error {
+	for sku, a := range applied {
+		current, err := s.Applied(ctx, sku)
+		if err != nil {
+			return err
+		}
+		current.Stock = max(current.Stock, a.Stock)
+		current.Price = max(current.Price, a.Price)
+
+		data, err := json.Marshal(current)
+		if err != nil {
+			return err
+		}
+		if err := s.store.Put(ctx, s.key(sku), data); err != nil {
+			return fmt.Errorf("failed to save inventory sequence of %s: %w", sku, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package marketplace
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/storage"
+)
+
+func newTestStore(t *testing.T) storage.Store {
+	t.Helper()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func TestVersionClockIncreases(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	clock := NewVersionClock(store)
+	now := models.VersionAt(time.Now())
+
+	first, err := clock.Next(ctx, "prod_1", now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first != now {
+		t.Errorf("first version %d, want the requested %d", first, now)
+	}
+
+	// the same instant, or one in the past, still moves forward
+	for _, requested := range []int64{now, now - 1000, 0} {
+		next, err := clock.Next(ctx, "prod_1", requested)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if next != first+1 {
+			t.Errorf("requested %d gave %d, want %d", requested, next, first+1)
+		}
+		first = next
+	}
+
+	// products are independent, and the counter survives a restart
+	if v, _ := clock.Next(ctx, "prod_2", 0); v != 1 {
+		t.Errorf("first version of prod_2 is %d, want 1", v)
+	}
+	if v, _ := NewVersionClock(store).Next(ctx, "prod_1", 0); v != first+1 {
+		t.Errorf("version after restart is %d, want %d", v, first+1)
+	}
+}
+
+func TestVersionClockRange(t *testing.T) {
+	ctx := context.Background()
+	clock := NewVersionClock(newTestStore(t))
+
+	for _, requested := range []int64{-1, models.MaxVersion + 1} {
+		if _, err := clock.Next(ctx, "prod_1", requested); err == nil {
+			t.Errorf("requested %d was accepted", requested)
+		}
+	}
+	if v, err := clock.Next(ctx, "prod_1", models.MaxVersion); err != nil || v != models.MaxVersion {
+		t.Fatalf("requested MaxVersion gave %d (%v)", v, err)
+	}
+	if _, err := clock.Next(ctx, "prod_1", 0); err == nil {
+		t.Error("versions past MaxVersion were handed out")
+	}
+}
+
+func TestSequencerRecordsHighestPositions(t *testing.T) {
+	ctx := context.Background()
+	store := newTestStore(t)
+	seq := NewSequencer(store, "amazon")
+
+	if applied, err := seq.Applied(ctx, "SKU/1"); err != nil || applied != (Applied{}) {
+		t.Fatalf("unseen SKU has %+v (%v), want nothing applied", applied, err)
+	}
+
+	steps := []map[string]Applied{
+		{"SKU/1": {Stock: 5}},
+		{"SKU/1": {Price: 7}, "SKU-2": {Stock: 1}},
+		{"SKU/1": {Stock: 3, Price: 9}}, // the stock is older than what was applied
+	}
+	for _, step := range steps {
+		if err := seq.Record(ctx, step); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if applied, _ := seq.Applied(ctx, "SKU/1"); applied != (Applied{Stock: 5, Price: 9}) {
+		t.Errorf("SKU/1 has %+v, want stock 5 and price 9", applied)
+	}
+	if applied, _ := seq.Applied(ctx, "SKU-2"); applied != (Applied{Stock: 1}) {
+		t.Errorf("SKU-2 has %+v, want stock 1", applied)
+	}
+
+	// another replica, and another marketplace, read the same store
+	if applied, _ := NewSequencer(store, "amazon").Applied(ctx, "SKU/1"); applied != (Applied{Stock: 5, Price: 9}) {
+		t.Errorf("a new sequencer sees %+v", applied)
+	}
+	if applied, _ := NewSequencer(store, "trendyol").Applied(ctx, "SKU/1"); applied != (Applied{}) {
+		t.Errorf("trendyol sees amazon's positions %+v", applied)
+	}
+}
+
+func TestChangesTakeTheProducersOrder(t *testing.T) {
+	stock := 4
+	at := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	older := models.StockChange{ProductID: "prod_1", Marketplace: "all", Stock: &stock, Timestamp: at}
+	newer := older
+	newer.Timestamp = at.Add(time.Millisecond)
+
+	if older.Sequence() >= newer.Sequence() {
+		t.Errorf("older change sequenced at %d, newer at %d", older.Sequence(), newer.Sequence())
+	}
+	stamped := older
+	stamped.Version = 42
+	if stamped.Sequence() != 42 {
+		t.Errorf("stamped version ignored: %d", stamped.Sequence())
+	}
+
+	missing := older
+	missing.Timestamp = time.Time{}
+	if err := missing.Validate(); err == nil {
+		t.Error("a change with neither version nor timestamp validated")
+	}
+}
@@ -0,0 +1,497 @@
+package marketplace
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"stox-rabbitmq/internal/config"
+	"stox-rabbitmq/internal/listing"
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/orders"
+	"stox-rabbitmq/internal/rabbitmq"
+	"stox-rabbitmq/internal/schema"
+	"stox-rabbitmq/internal/storage"
+	"stox-rabbitmq/internal/taxonomy"
+)
+
+// ServiceConfig is what sets one marketplace service apart from the others
+type ServiceConfig struct {
+	Marketplace string // e.g. amazon; names the service's queues, API client and catalog
+	Title       string // e.g. Amazon, for logs
+	Icon        string // logged when the service starts and stops
+	ListingIcon string // logged with each listing
+	IDPrefix    string // of listing and event IDs, e.g. amz
+	ListingID   string // what the marketplace calls a listing ID, e.g. ASIN
+
+	// WebhookAddr is where order webhooks are received unless WEBHOOK_ADDR is set
+	WebhookAddr string
+
+	// Convert turns USD prices into the marketplace's currency (nil keeps
+	// USD); Currency and Symbol name that currency for events and logs
+	Convert  func(usd float64) float64
+	Currency string
+	Symbol   string
+
+	Markup       float64 // applied to converted prices of new listings
+	InitialStock int     // mock stock of new listings
+
+	// Simulate sends demo orders to the order webhook, as the marketplace would
+	Simulate func(webhookURL, secret string)
+}
+
+// service is a running marketplace service
+type service struct {
+	ServiceConfig
+	client     *rabbitmq.Client
+	api        *Client
+	validator  *listing.Validator
+	categories *taxonomy.Mapper
+	catalog    *orders.Catalog
+}
+
+// RunService runs a marketplace service until it is interrupted: it lists
+// products, applies partitioned stock and price changes, and ingests and
+// processes orders
+func RunService(sc ServiceConfig) {
+	log.Printf("%s Starting %s Marketplace Service...", sc.Icon, sc.Title)
+
+	// Load configuration
+	cfg := config.LoadConfig()
+	cfg.ServiceName = sc.Marketplace + "-service"
+
+	listingsQueue := sc.Marketplace + "_listings"
+	ordersQueue := sc.Marketplace + "_orders"
+	syncQueue := sc.Marketplace + "_sync"
+
+	// Create RabbitMQ client
+	client, err := rabbitmq.NewClient(rabbitmq.Config{
+		URL:             cfg.GetRabbitMQURL(),
+		ValidateSchemas: cfg.ValidateSchemas,
+
+		Compression:          cfg.Compression,
+		CompressionThreshold: cfg.CompressionThreshold,
+		MaxDecompressedSize:  cfg.MaxDecompressedSize,
+
+		ClaimCheckDir:       cfg.ClaimCheckDir,
+		ClaimCheckThreshold: cfg.ClaimCheckThreshold,
+		ClaimCheckTTL:       cfg.ClaimCheckTTL,
+
+		Prefetch: cfg.Prefetch,
+	})
+	if err != nil {
+		log.Fatalf("Failed to create RabbitMQ client: %v", err)
+	}
+	defer client.Close()
+
+	// Setup exchanges
+	err = client.SetupExchanges()
+	if err != nil {
+		log.Fatalf("Failed to setup exchanges: %v", err)
+	}
+
+	// Declare queues
+	queues := []struct {
+		name     string
+		exchange string
+		routing  string
+	}{
+		{listingsQueue, "stox.listings", ""},                           // Fanout - receives all listings
+		{ordersQueue, "stox.orders", "order." + sc.Marketplace + ".*"}, // Topic - the marketplace's orders
+	}
+
+	for _, q := range queues {
+		err = client.DeclareQueue(q.name, q.exchange, q.routing)
+		if err != nil {
+			log.Fatalf("Failed to declare queue %s: %v", q.name, err)
+		}
+	}
+
+	// Sync operations are partitioned by product and applied in order, so one
+	// replica at a time consumes each partition
+	for p := 0; p < cfg.SyncPartitions; p++ {
+		queue := rabbitmq.PartitionQueue(syncQueue, p)
+		err = client.DeclareQueueWithArgs(queue, "stox.sync", queue, rabbitmq.SingleActiveConsumer)
+		if err != nil {
+			log.Fatalf("Failed to declare queue %s: %v", queue, err)
+		}
+	}
+
+	// Sync messages left in the unpartitioned queue move to their partitions
+	err = client.RetireQueue(syncQueue, "stox.sync", syncQueue, func(d rabbitmq.Delivery) (string, error) {
+		change, err := DecodeChange(d)
+		if err != nil {
+			return "", err
+		}
+		return rabbitmq.PartitionQueue(syncQueue, rabbitmq.Partition(change.ProductID, cfg.SyncPartitions)), nil
+	})
+	if err != nil {
+		log.Fatalf("Failed to retire queue %s: %v", syncQueue, err)
+	}
+
+	// Listings that fail validation wait for manual review
+	err = client.DeclareQueue(listing.ReviewQueue, "stox.sync", listing.ReviewQueue)
+	if err != nil {
+		log.Fatalf("Failed to declare queue %s: %v", listing.ReviewQueue, err)
+	}
+
+	validator, err := listing.NewValidator(sc.Marketplace)
+	if err != nil {
+		log.Fatalf("Failed to create listing validator: %v", err)
+	}
+
+	categories, err := taxonomy.Load(cfg.TaxonomyDir)
+	if err != nil {
+		log.Fatalf("Failed to load category taxonomy: %v", err)
+	}
+
+	// Orders are ingested from webhooks and, when an API URL is configured, by polling;
+	// the polling cursor and the inventory sequence are kept in the blob store
+	store, err := storage.New(cfg.Storage)
+	if err != nil {
+		log.Fatalf("Failed to open storage: %v", err)
+	}
+
+	// Orders for listed products resolve through the catalog, rebuilt from storage
+	catalog := orders.NewCatalog(store, sc.Marketplace)
+	listed, err := catalog.Load(context.Background())
+	if err != nil {
+		log.Fatalf("Failed to load order catalog: %v", err)
+	}
+	log.Printf("📚 Loaded %d listed products into the order catalog", listed)
+
+	ingester, err := orders.NewIngester(sc.Marketplace, client, store, catalog)
+	if err != nil {
+		log.Fatalf("Failed to create order ingester: %v", err)
+	}
+
+	marketplaceConfig := cfg.Marketplaces[sc.Marketplace]
+	limits, err := ParseLimits(marketplaceConfig.RateLimits)
+	if err != nil {
+		log.Fatalf("Invalid %s_RATE_LIMITS: %v", strings.ToUpper(sc.Marketplace), err)
+	}
+	api, err := NewClient(ClientConfig{
+		Marketplace:      sc.Marketplace,
+		URL:              marketplaceConfig.APIURL,
+		Limits:           limits,
+		BreakerThreshold: cfg.BreakerThreshold,
+		BreakerCooldown:  cfg.BreakerCooldown,
+		Sequencer:        NewSequencer(store, sc.Marketplace),
+	})
+	if err != nil {
+		log.Fatalf("Failed to create %s API client: %v", sc.Title, err)
+	}
+
+	webhookAddr := cfg.WebhookAddr
+	if webhookAddr == "" {
+		webhookAddr = sc.WebhookAddr
+	}
+
+	// Validate messages from non-Go producers that send no schema headers
+	client.ExpectSchema(listingsQueue, "product")
+	client.ExpectSchema(ordersQueue, "order")
+	for p := 0; p < cfg.SyncPartitions; p++ {
+		client.ExpectSchema(rabbitmq.PartitionQueue(syncQueue, p), LegacySchema)
+	}
+
+	s := &service{
+		ServiceConfig: sc,
+		client:        client,
+		api:           api,
+		validator:     validator,
+		categories:    categories,
+		catalog:       catalog,
+	}
+
+	log.Printf("✅ %s Service initialized successfully", sc.Title)
+
+	// Listings and sync operations call the marketplace API; they pause while it is unavailable
+	runner := NewRunner(api)
+	err = runner.Consume(client, listingsQueue, s.handleListing)
+	if err != nil {
+		log.Fatalf("Failed to consume %s: %v", listingsQueue, err)
+	}
+
+	// The replicas of this service share the sync partitions
+	coordinator, err := client.NewCoordinator(rabbitmq.CoordinatorConfig{
+		Group:      syncQueue,
+		Partitions: cfg.SyncPartitions,
+		Assign: func(p int) error {
+			queue := rabbitmq.PartitionQueue(syncQueue, p)
+			return runner.ConsumeBatch(client, queue, cfg.SyncBatchSize, cfg.SyncBatchWindow, s.handleSyncBatch)
+		},
+		Revoke: func(p int) {
+			err := runner.Release(rabbitmq.PartitionQueue(syncQueue, p))
+			if err != nil {
+				log.Printf("⚠️  %v", err)
+			}
+		},
+	})
+	if err != nil {
+		log.Fatalf("Failed to create sync coordinator: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		err := coordinator.Run(ctx)
+		if err != nil {
+			log.Printf("%s sync coordinator error: %v", sc.Title, err)
+		}
+	}()
+
+	// Start consuming orders
+	go func() {
+		err := client.ConsumeMessages(ordersQueue, s.handleOrder)
+		if err != nil {
+			log.Printf("%s orders consumer error: %v", sc.Title, err)
+		}
+	}()
+
+	if cfg.Compression != "" {
+		go client.ReportCompressionStats(time.Minute)
+	}
+
+	// Receive order webhooks, when their secret is configured
+	if marketplaceConfig.WebhookSecret != "" {
+		go func() {
+			mux := http.NewServeMux()
+			mux.Handle(orders.WebhookPath(sc.Marketplace), ingester.Webhook(marketplaceConfig.WebhookSecret))
+
+			log.Printf("🔔 %s order webhooks on %s%s", sc.Title, webhookAddr, orders.WebhookPath(sc.Marketplace))
+			err := http.ListenAndServe(webhookAddr, mux)
+			if err != nil {
+				log.Printf("%s webhook server error: %v", sc.Title, err)
+			}
+		}()
+	} else {
+		log.Printf("⚠️  %s_WEBHOOK_SECRET is not set; %s order webhooks are disabled", strings.ToUpper(sc.Marketplace), sc.Title)
+	}
+
+	// Poll the order API
+	go ingester.Poll(ctx, marketplaceConfig.APIURL, cfg.OrderPollInterval)
+
+	// Simulate periodic orders
+	if sc.Simulate != nil && marketplaceConfig.WebhookSecret != "" {
+		go sc.Simulate(orders.LocalURL(webhookAddr, sc.Marketplace), marketplaceConfig.WebhookSecret)
+	}
+
+	// Wait for interrupt signal
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt, syscall.SIGTERM)
+	<-c
+
+	log.Printf("%s %s Service shutting down...", sc.Icon, sc.Title)
+	coordinator.Stop()
+}
+
+// convert turns a USD price into the marketplace's currency
+func (s *service) convert(usd float64) float64 {
+	if s.Convert == nil {
+		return usd
+	}
+	return s.Convert(usd)
+}
+
+// handleListing lists a product on the marketplace. The listings fanout also
+// carries the services' own listing events, which are skipped.
+func (s *service) handleListing(d rabbitmq.Delivery) error {
+	if name, _ := d.Headers[schema.HeaderName].(string); name != "" && name != "product" {
+		log.Printf("⏭️  %s: Skipping %s message on the listings exchange", s.Title, name)
+		return nil
+	}
+
+	var product models.Product
+	err := json.Unmarshal(d.Body, &product)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+
+	log.Printf("%s %s: Processing listing for product %s", s.ListingIcon, s.Title, product.ID)
+
+	// Check the content in the marketplace's locale and category, auto-fixing what the rules allow
+	category := s.categories.Map(s.Marketplace, product.Category)
+	result := s.validator.Validate(listing.NewListing(s.Marketplace, product, category))
+	for _, v := range result.Fixed {
+		log.Printf("  🔧 Auto-fixed %s: %s", v.Rule, v.Message)
+	}
+	if !result.OK() {
+		return s.requestReview(product, result)
+	}
+	content := result.Listing
+
+	created := models.MarketplaceListing{
+		ID:          fmt.Sprintf("%s_%s_%d", s.IDPrefix, product.ID, time.Now().Unix()),
+		ProductID:   product.ID,
+		Marketplace: s.Marketplace,
+		Status:      "pending",
+		Price:       s.convert(product.Price) * s.Markup,
+		Stock:       s.InitialStock,
+		Title:       content.Title,
+		Locale:      content.Locale,
+		CategoryID:  content.Category.Category.ID,
+		Category:    content.Category.Category.Path,
+		LastSyncAt:  time.Now(),
+	}
+
+	// Variants are listed the way the marketplace's policy groups them
+	created.VariationTheme, created.Variants = listing.MapVariants(s.validator.Policy(), content, func(v models.Variant) float64 {
+		return s.convert(product.VariantPrice(v)) * s.Markup
+	})
+	if len(created.Variants) > 0 {
+		created.Stock = 0
+		for _, v := range created.Variants {
+			created.Stock += v.Stock
+		}
+	}
+
+	resp, err := s.api.CreateListing(context.Background(), created)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.Rejected() {
+		result.Violations = append(result.Violations, models.ListingViolation{
+			Rule:    "marketplace_rejection",
+			Field:   "listing",
+			Message: apiErr.Message,
+		})
+		return s.requestReview(product, result)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s listing: %w", s.Title, err)
+	}
+	created.ListingID, created.URL, created.Status = resp.ListingID, resp.URL, resp.Status
+	for i := range created.Variants {
+		created.Variants[i].ListingID = resp.Variants[created.Variants[i].SKU]
+	}
+
+	log.Printf("  ✅ Listed on %s:", s.Title)
+	log.Printf("    %s: %s", s.ListingID, created.ListingID)
+	log.Printf("    Price: %s%.2f", s.Symbol, created.Price)
+	log.Printf("    URL: %s", created.URL)
+	log.Printf("    Title [%s]: %s", created.Locale, created.Title)
+	log.Printf("    Category: %s (%s, %s)", created.Category, created.CategoryID, content.Category.Source)
+	for _, v := range created.Variants {
+		log.Printf("    Variant %s (%s): %v, stock %d", v.SKU, v.ListingID, v.Attributes, v.Stock)
+	}
+
+	// Orders for the product and its variant SKUs can now be resolved
+	if err := s.catalog.Add(context.Background(), product); err != nil {
+		log.Printf("  ⚠️  %v", err)
+	}
+
+	// Publish listing event
+	event, err := models.NewProcessingEvent(
+		fmt.Sprintf("evt_%s_%d", s.IDPrefix, time.Now().Unix()),
+		s.Marketplace+"-service",
+		product.ID,
+		models.MarketplaceListed{
+			Marketplace: s.Marketplace,
+			ListingID:   created.ListingID,
+			UserID:      product.UserID,
+			Price:       created.Price,
+			Currency:    s.Currency,
+			URL:         created.URL,
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to build listing event: %w", err)
+	}
+
+	err = s.client.PublishMessage("stox.listings", "event.listed", event)
+	if err != nil {
+		log.Printf("Warning: Failed to publish listing event: %v", err)
+	}
+
+	return nil
+}
+
+// requestReview sends a product whose listing failed validation to manual review
+func (s *service) requestReview(product models.Product, result listing.Result) error {
+	for _, v := range result.Violations {
+		log.Printf("  ⚠️  %s: %s", v.Rule, v.Message)
+	}
+
+	review := models.ListingReview{
+		Product:     product,
+		Marketplace: s.Marketplace,
+		Violations:  result.Violations,
+		Fixed:       result.Fixed,
+		CreatedAt:   time.Now(),
+	}
+	err := s.client.PublishMessage("stox.sync", listing.ReviewQueue, review)
+	if err != nil {
+		return fmt.Errorf("failed to request listing review: %w", err)
+	}
+
+	log.Printf("  📝 %s: %d violation(s), product %s sent to %s", s.Title, len(result.Violations), product.ID, listing.ReviewQueue)
+	return nil
+}
+
+// handleOrder processes an incoming order
+func (s *service) handleOrder(data []byte) error {
+	var order models.Order
+	err := json.Unmarshal(data, &order)
+	if err != nil {
+		return fmt.Errorf("failed to unmarshal order: %w", err)
+	}
+
+	log.Printf("📦 %s: Processing order %s (%s)", s.Title, order.OrderID, order.Region)
+
+	// Mock order processing
+	order.Status = "processing"
+	order.UpdatedAt = time.Now()
+
+	log.Printf("  ✅ Order processed:")
+	log.Printf("    Product: %s (SKU %s)", order.ProductID, order.SKU)
+	log.Printf("    Quantity: %d at %.2f %s", order.Quantity, order.Price, order.Currency)
+	log.Printf("    Customer: %s", order.CustomerInfo.Name)
+
+	return nil
+}
+
+// handleSyncBatch applies a batch of stock and price changes with bulk API
+// calls, keeping only the latest stock and price per SKU and discarding stale ones
+func (s *service) handleSyncBatch(deliveries []rabbitmq.Delivery) []error {
+	errs := make([]error, len(deliveries))
+	var changes []Change
+	var positions []int
+	for i, d := range deliveries {
+		change, err := DecodeChange(d)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		changes = append(changes, change)
+		positions = append(positions, i)
+	}
+
+	results, changeErrs := s.api.SyncBatch(context.Background(), changes, s.Convert)
+	for n, err := range changeErrs {
+		if err != nil {
+			errs[positions[n]] = fmt.Errorf("failed to sync %s inventory: %w", s.Title, err)
+		}
+	}
+
+	if len(results) > 0 {
+		log.Printf("🔄 %s: Synced %d changes as %d bulk items", s.Title, len(changes), len(results))
+	}
+	for _, result := range results {
+		switch {
+		case result.OK():
+			log.Printf("  ✅ %s", result.SKU)
+		case result.Skipped():
+			log.Printf("  ⏭️  %s: %s", result.SKU, result.Error)
+		default:
+			log.Printf("  ❌ %s: %s", result.SKU, result.Error)
+		}
+	}
+
+	return errs
+}
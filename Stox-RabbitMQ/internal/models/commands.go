@@ -0,0 +1,136 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// MaxVersion bounds change versions so that they stay exact in codecs that
+// carry numbers as doubles
+const MaxVersion = 1<<53 - 1
+
+// VersionAt derives a change version from the time the change was made.
+// Microseconds since the epoch stay below MaxVersion until the year 2255.
+func VersionAt(t time.Time) int64 {
+	return t.UnixMicro()
+}
+
+// Target narrows the marketplaces a change goes to. Each set list applies:
+// only the named marketplaces, only those with one of the tags, none of the
+// excluded ones.
+type Target struct {
+	Marketplaces []string `json:"marketplaces,omitempty"`
+	Tags         []string `json:"tags,omitempty"`
+	Exclude      []string `json:"exclude,omitempty"`
+}
+
+// StockChange sets the stock of a product, or of one variant SKU, on a
+// marketplace ("all" for every marketplace, optionally narrowed by Target).
+// It never touches prices.
+type StockChange struct {
+	ProductID   string    `json:"product_id" jsonschema:"required"`
+	Marketplace string    `json:"marketplace" jsonschema:"required"`
+	Target      *Target   `json:"target,omitempty"`
+	SKU         string    `json:"sku,omitempty"` // variant SKU; empty changes the whole product
+	Stock       *int      `json:"stock" jsonschema:"required,minimum=0"`
+	Timestamp   time.Time `json:"timestamp"`
+	Version     int64     `json:"version,omitempty"` // per-product sequence; newer changes have higher versions
+}
+
+// Sequence orders the change among its product's changes: the version its
+// producer stamped, or else the version of its timestamp
+func (c StockChange) Sequence() int64 {
+	if c.Version != 0 {
+		return c.Version
+	}
+	return VersionAt(c.Timestamp)
+}
+
+// PriceChange sets the price of a product, or of one variant SKU, on a
+// marketplace ("all" for every marketplace, optionally narrowed by Target).
+// It never touches stock.
+type PriceChange struct {
+	ProductID   string    `json:"product_id" jsonschema:"required"`
+	Marketplace string    `json:"marketplace" jsonschema:"required"`
+	Target      *Target   `json:"target,omitempty"`
+	SKU         string    `json:"sku,omitempty"`                                  // variant SKU; empty changes the whole product
+	Price       *float64  `json:"price" jsonschema:"required,exclusiveMinimum=0"` // in USD; marketplaces convert
+	Timestamp   time.Time `json:"timestamp"`
+	Version     int64     `json:"version,omitempty"`
+}
+
+// Sequence orders the change among its product's changes, like StockChange.Sequence
+func (c PriceChange) Sequence() int64 {
+	if c.Version != 0 {
+		return c.Version
+	}
+	return VersionAt(c.Timestamp)
+}
+
+// Validate checks a StockChange
+func (c StockChange) Validate() error {
+	switch {
+	case c.ProductID == "":
+		return fmt.Errorf("stock_change: product_id is required")
+	case c.Marketplace == "":
+		return fmt.Errorf("stock_change: marketplace is required")
+	case c.Version < 0 || c.Version > MaxVersion:
+		return fmt.Errorf("stock_change: version %d is out of range", c.Version)
+	case c.Sequence() <= 0 || c.Sequence() > MaxVersion:
+		return fmt.Errorf("stock_change: a version or a timestamp is required")
+	case c.Stock == nil:
+		return fmt.Errorf("stock_change: stock is required")
+	case *c.Stock < 0:
+		return fmt.Errorf("stock_change: stock %d must not be negative", *c.Stock)
+	}
+	return nil
+}
+
+// Validate checks a PriceChange
+func (c PriceChange) Validate() error {
+	switch {
+	case c.ProductID == "":
+		return fmt.Errorf("price_change: product_id is required")
+	case c.Marketplace == "":
+		return fmt.Errorf("price_change: marketplace is required")
+	case c.Version < 0 || c.Version > MaxVersion:
+		return fmt.Errorf("price_change: version %d is out of range", c.Version)
+	case c.Sequence() <= 0 || c.Sequence() > MaxVersion:
+		return fmt.Errorf("price_change: a version or a timestamp is required")
+	case c.Price == nil:
+		return fmt.Errorf("price_change: price is required")
+	case *c.Price <= 0:
+		return fmt.Errorf("price_change: price %.2f must be positive", *c.Price)
+	}
+	return nil
+}
+
+// Commands splits a legacy InventoryUpdate into the typed changes its
+// UpdateType names; only those fields are taken from it
+func (u InventoryUpdate) Commands() (*StockChange, *PriceChange) {
+	var stock *StockChange
+	var price *PriceChange
+	if u.UpdateType == "stock" || u.UpdateType == "both" {
+		s := u.Stock
+		stock = &StockChange{
+			ProductID:   u.ProductID,
+			Marketplace: u.Marketplace,
+			SKU:         u.SKU,
+			Stock:       &s,
+			Timestamp:   u.Timestamp,
+			Version:     u.Version,
+		}
+	}
+	if u.UpdateType == "price" || u.UpdateType == "both" {
+		p := u.Price
+		price = &PriceChange{
+			ProductID:   u.ProductID,
+			Marketplace: u.Marketplace,
+			SKU:         u.SKU,
+			Price:       &p,
+			Timestamp:   u.Timestamp,
+			Version:     u.Version,
+		}
+	}
+	return stock, price
+}
@@ -0,0 +1,205 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Processing event types
+const (
+	EventImageUploaded     = "image_uploaded"
+	EventImageRejected     = "image_rejected"
+	EventAIEnhanced        = "ai_enhanced"
+	EventSEOGenerated      = "seo_generated"
+	EventMarketplaceListed = "marketplace_listed"
+)
+
+// ErrUnknownEventType is returned when no payload is registered for an event type
+var ErrUnknownEventType = errors.New("unknown event type")
+
+// EventPayload is the typed content of ProcessingEvent.Data
+type EventPayload interface {
+	EventType() string
+	Validate() error
+}
+
+// ImageUploaded is published by image-service once originals are stored
+type ImageUploaded struct {
+	ImageCount int   `json:"image_count"`
+	TotalSize  int64 `json:"total_size"`
+}
+
+// RejectedImage lists why one image failed validation
+type RejectedImage struct {
+	ImageID string   `json:"image_id"`
+	Reasons []string `json:"reasons"`
+}
+
+// ImageRejected is published by image-service for images that are not forwarded to AI
+type ImageRejected struct {
+	Images    []RejectedImage `json:"images"`
+	Remaining int             `json:"remaining"` // valid images still forwarded
+}
+
+// AIEnhanced is published by ai-service after image enhancement
+type AIEnhanced struct {
+	WorkerID       int      `json:"worker_id"`
+	ProcessingTime float64  `json:"processing_time"` // seconds
+	ImagesEnhanced int      `json:"images_enhanced"`
+	Enhancements   []string `json:"enhancements"`
+	Provider       string   `json:"provider,omitempty"` // AI providers that produced the images
+	Cost           float64  `json:"cost,omitempty"`     // USD
+}
+
+// SEOGenerated is published by seo-service after content generation
+type SEOGenerated struct {
+	SEOScore     float64  `json:"seo_score"`
+	TitleLength  int      `json:"title_length"`
+	DescLength   int      `json:"desc_length"`
+	KeywordCount int      `json:"keyword_count"`
+	GeneratedBy  string   `json:"generated_by"`
+	Provider     string   `json:"provider,omitempty"`
+	Tokens       int      `json:"tokens,omitempty"` // input + output
+	Cost         float64  `json:"cost,omitempty"`   // USD
+	Locales      []string `json:"locales,omitempty"`
+}
+
+// MarketplaceListed is published by marketplace services when a listing goes live
+type MarketplaceListed struct {
+	Marketplace string  `json:"marketplace"`
+	ListingID   string  `json:"listing_id"`
+	UserID      string  `json:"user_id,omitempty"` // the product's owner, whose account it is listed with
+	Price       float64 `json:"price"`
+	Currency    string  `json:"currency,omitempty"`
+	URL         string  `json:"url"`
+}
+
+func (ImageUploaded) EventType() string     { return EventImageUploaded }
+func (ImageRejected) EventType() string     { return EventImageRejected }
+func (AIEnhanced) EventType() string        { return EventAIEnhanced }
+func (SEOGenerated) EventType() string      { return EventSEOGenerated }
+func (MarketplaceListed) EventType() string { return EventMarketplaceListed }
+
+// Validate checks an ImageUploaded payload
+func (p ImageUploaded) Validate() error {
+	if p.ImageCount < 0 || p.TotalSize < 0 {
+		return fmt.Errorf("image_uploaded: counts must not be negative")
+	}
+	return nil
+}
+
+// Validate checks an ImageRejected payload
+func (p ImageRejected) Validate() error {
+	if len(p.Images) == 0 {
+		return fmt.Errorf("image_rejected: at least one image is required")
+	}
+	for _, img := range p.Images {
+		if img.ImageID == "" || len(img.Reasons) == 0 {
+			return fmt.Errorf("image_rejected: image_id and reasons are required")
+		}
+	}
+	return nil
+}
+
+// Validate checks an AIEnhanced payload
+func (p AIEnhanced) Validate() error {
+	if p.ImagesEnhanced < 0 || p.ProcessingTime < 0 {
+		return fmt.Errorf("ai_enhanced: counts must not be negative")
+	}
+	return nil
+}
+
+// Validate checks a SEOGenerated payload
+func (p SEOGenerated) Validate() error {
+	if p.SEOScore < 0 || p.SEOScore > 10 {
+		return fmt.Errorf("seo_generated: seo_score %.1f out of range 0-10", p.SEOScore)
+	}
+	return nil
+}
+
+// Validate checks a MarketplaceListed payload
+func (p MarketplaceListed) Validate() error {
+	if p.Marketplace == "" {
+		return fmt.Errorf("marketplace_listed: marketplace is required")
+	}
+	if p.ListingID == "" {
+		return fmt.Errorf("marketplace_listed: listing_id is required")
+	}
+	return nil
+}
+
+var (
+	payloadMu       sync.RWMutex
+	payloadRegistry = map[string]func() EventPayload{
+		EventImageUploaded:     func() EventPayload { return &ImageUploaded{} },
+		EventImageRejected:     func() EventPayload { return &ImageRejected{} },
+		EventAIEnhanced:        func() EventPayload { return &AIEnhanced{} },
+		EventSEOGenerated:      func() EventPayload { return &SEOGenerated{} },
+		EventMarketplaceListed: func() EventPayload { return &MarketplaceListed{} },
+	}
+)
+
+// RegisterPayload registers a payload constructor for an event type.
+// The constructor must return a pointer so the payload can be decoded into.
+func RegisterPayload(eventType string, factory func() EventPayload) {
+	payloadMu.Lock()
+	defer payloadMu.Unlock()
+	payloadRegistry[eventType] = factory
+}
+
+// NewProcessingEvent builds an event whose Data carries the given payload
+func NewProcessingEvent(id, source, productID string, payload EventPayload) (ProcessingEvent, error) {
+	if err := payload.Validate(); err != nil {
+		return ProcessingEvent{}, err
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return ProcessingEvent{}, fmt.Errorf("failed to marshal %s payload: %w", payload.EventType(), err)
+	}
+
+	var data map[string]interface{}
+	if err := json.Unmarshal(raw, &data); err != nil {
+		return ProcessingEvent{}, fmt.Errorf("failed to convert %s payload: %w", payload.EventType(), err)
+	}
+
+	return ProcessingEvent{
+		ID:        id,
+		Type:      payload.EventType(),
+		ProductID: productID,
+		Data:      data,
+		Timestamp: time.Now(),
+		Source:    source,
+	}, nil
+}
+
+// Payload decodes Data into the registered payload type for the event and validates it.
+// Events produced before typed payloads existed decode the same way, since the
+// wire format of Data is unchanged.
+func (e ProcessingEvent) Payload() (EventPayload, error) {
+	payloadMu.RLock()
+	factory, ok := payloadRegistry[e.Type]
+	payloadMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("%w: %q", ErrUnknownEventType, e.Type)
+	}
+
+	raw, err := json.Marshal(e.Data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %s data: %w", e.Type, err)
+	}
+
+	payload := factory()
+	if err := json.Unmarshal(raw, payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s payload: %w", e.Type, err)
+	}
+
+	if err := payload.Validate(); err != nil {
+		return nil, err
+	}
+
+	return payload, nil
+}
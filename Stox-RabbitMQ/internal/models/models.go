@@ -4,106 +4,215 @@ import "time"
 
 // Product represents a product being processed through the platform
 type Product struct {
-	ID          string    `json:"id"`
-	UserID      string    `json:"user_id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Price       float64   `json:"price"`
-	Currency    string    `json:"currency"`
-	Category    string    `json:"category"`
-	Images      []Image   `json:"images"`
-	SEO         SEOData   `json:"seo"`
-	Status      string    `json:"status"` // processing, enhanced, listed, error
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID                string             `json:"id" jsonschema:"required"`
+	UserID            string             `json:"user_id"`
+	Title             string             `json:"title"`
+	Description       string             `json:"description"`
+	Price             float64            `json:"price" jsonschema:"minimum=0"`
+	Currency          string             `json:"currency"`
+	Category          string             `json:"category"`
+	Images            []Image            `json:"images"`
+	SEO               SEOData            `json:"seo"`
+	LocalizedSEO      map[string]SEOData `json:"localized_seo,omitempty"`      // locale (tr-TR, en-US, de-DE) -> content
+	Attributes        map[string]string  `json:"attributes,omitempty"`         // brand, color, material, ...
+	VariantAttributes []string           `json:"variant_attributes,omitempty"` // attributes variants differ in, e.g. color, size
+	Variants          []Variant          `json:"variants,omitempty"`           // empty for single-SKU products
+	Status            string             `json:"status"`                       // processing, enhanced, listed, error
+	CreatedAt         time.Time          `json:"created_at"`
+	UpdatedAt         time.Time          `json:"updated_at"`
 }
 
 // Image represents an image in the system
 type Image struct {
-	ID           string `json:"id"`
-	OriginalURL  string `json:"original_url"`
-	EnhancedURL  string `json:"enhanced_url,omitempty"`
-	S3Key        string `json:"s3_key"`
-	Size         int64  `json:"size"`
-	Width        int    `json:"width"`
-	Height       int    `json:"height"`
-	Format       string `json:"format"`
-	IsProcessed  bool   `json:"is_processed"`
-	ProcessingAt time.Time `json:"processing_at,omitempty"`
+	ID           string            `json:"id"`
+	OriginalURL  string            `json:"original_url"`
+	EnhancedURL  string            `json:"enhanced_url,omitempty"`
+	S3Key        string            `json:"s3_key"`
+	Size         int64             `json:"size"`
+	Width        int               `json:"width"`
+	Height       int               `json:"height"`
+	Format       string            `json:"format"`
+	IsProcessed  bool              `json:"is_processed"`
+	ProcessingAt time.Time         `json:"processing_at,omitempty"`
+	Hash         string            `json:"hash,omitempty"`         // sha256 of the original, set by image-service
+	Renditions   map[string]string `json:"renditions,omitempty"`   // rendition name -> storage key, set by ai-service
+	Marketplaces []string          `json:"marketplaces,omitempty"` // marketplaces whose minimum resolution the original meets, set by image-service
+
+	// Data carries uploaded bytes when there is no OriginalURL to fetch.
+	// The image service clears it once the original is stored.
+	Data []byte `json:"data,omitempty"`
+}
+
+// Variant is one purchasable SKU of a product, e.g. a color and size combination
+type Variant struct {
+	SKU        string            `json:"sku" jsonschema:"required"`
+	Attributes map[string]string `json:"attributes"`                             // a value for each of the product's variant_attributes
+	Price      float64           `json:"price,omitempty" jsonschema:"minimum=0"` // 0 uses the product price
+	Stock      int               `json:"stock" jsonschema:"minimum=0"`
+	ImageIDs   []string          `json:"image_ids,omitempty"` // product images showing this variant
 }
 
 // SEOData contains SEO-optimized content
 type SEOData struct {
-	Title       string   `json:"title"`
-	Description string   `json:"description"`
-	Keywords    []string `json:"keywords"`
+	Title       string            `json:"title"`
+	Description string            `json:"description"`
+	Keywords    []string          `json:"keywords"`
 	MetaTags    map[string]string `json:"meta_tags"`
-	GeneratedBy string   `json:"generated_by"` // ai, manual
-	Score       float64  `json:"score"`        // SEO optimization score
+	GeneratedBy string            `json:"generated_by"` // ai, manual
+	Score       float64           `json:"score"`        // SEO optimization score
+	Locale      string            `json:"locale,omitempty"`
+	Breakdown   []SEOScoreItem    `json:"breakdown,omitempty"` // how Score was computed
+}
+
+// SEOScoreItem is one component of an SEO score
+type SEOScoreItem struct {
+	Name  string   `json:"name"`
+	Score float64  `json:"score"`
+	Max   float64  `json:"max"`
+	Notes []string `json:"notes,omitempty"`
+}
+
+// SEOFor returns the SEO content for a locale, falling back to the default content
+func (p Product) SEOFor(locale string) SEOData {
+	if seo, ok := p.LocalizedSEO[locale]; ok {
+		return seo
+	}
+	return p.SEO
+}
+
+// VariantPrice returns a variant's price, falling back to the product price
+func (p Product) VariantPrice(v Variant) float64 {
+	if v.Price > 0 {
+		return v.Price
+	}
+	return p.Price
+}
+
+// Variant returns the variant with a SKU
+func (p Product) Variant(sku string) (Variant, bool) {
+	for _, v := range p.Variants {
+		if v.SKU == sku {
+			return v, true
+		}
+	}
+	return Variant{}, false
+}
+
+// VariantOptions lists the distinct values of each variant attribute in
+// first-seen order, e.g. color -> [Black, Silver]
+func (p Product) VariantOptions() map[string][]string {
+	options := make(map[string][]string, len(p.VariantAttributes))
+	for _, name := range p.VariantAttributes {
+		seen := make(map[string]bool)
+		for _, v := range p.Variants {
+			if value := v.Attributes[name]; value != "" && !seen[value] {
+				seen[value] = true
+				options[name] = append(options[name], value)
+			}
+		}
+	}
+	return options
 }
 
 // MarketplaceListing represents a product listing on a marketplace
 type MarketplaceListing struct {
-	ID           string    `json:"id"`
-	ProductID    string    `json:"product_id"`
-	Marketplace  string    `json:"marketplace"` // amazon, trendyol, hepsiburada
-	ListingID    string    `json:"listing_id"`  // External marketplace ID
-	Status       string    `json:"status"`      // pending, active, rejected, paused
-	Price        float64   `json:"price"`
-	Stock        int       `json:"stock"`
-	URL          string    `json:"url"`
-	LastSyncAt   time.Time `json:"last_sync_at"`
-	ErrorMessage string    `json:"error_message,omitempty"`
+	ID             string           `json:"id"`
+	ProductID      string           `json:"product_id" jsonschema:"required"`
+	Marketplace    string           `json:"marketplace" jsonschema:"required"` // amazon, trendyol, hepsiburada
+	ListingID      string           `json:"listing_id"`                        // External marketplace ID
+	Status         string           `json:"status"`                            // pending, active, rejected, paused
+	Price          float64          `json:"price" jsonschema:"minimum=0"`
+	Stock          int              `json:"stock" jsonschema:"minimum=0"`
+	URL            string           `json:"url"`
+	Title          string           `json:"title,omitempty"`           // localized listing title
+	Locale         string           `json:"locale,omitempty"`          // content locale, e.g. tr-TR
+	CategoryID     string           `json:"category_id,omitempty"`     // marketplace category
+	Category       string           `json:"category,omitempty"`        // marketplace category path
+	VariationTheme string           `json:"variation_theme,omitempty"` // how the marketplace groups variants, e.g. SizeColor
+	Variants       []ListingVariant `json:"variants,omitempty"`
+	LastSyncAt     time.Time        `json:"last_sync_at"`
+	ErrorMessage   string           `json:"error_message,omitempty"`
+}
+
+// ListingVariant is a variant as listed on a marketplace
+type ListingVariant struct {
+	SKU        string            `json:"sku" jsonschema:"required"`
+	ListingID  string            `json:"listing_id"` // child ASIN, barcode, ...
+	Attributes map[string]string `json:"attributes"` // marketplace attribute names, e.g. Renk, Beden
+	Price      float64           `json:"price" jsonschema:"minimum=0"`
+	Stock      int               `json:"stock" jsonschema:"minimum=0"`
+	Images     []string          `json:"images,omitempty"` // image URLs
+}
+
+// ListingViolation is a marketplace listing rule the product does not meet
+type ListingViolation struct {
+	Rule    string `json:"rule" jsonschema:"required"`
+	Field   string `json:"field"` // title, description, images, attributes.<name>
+	Message string `json:"message"`
+}
+
+// ListingReview routes a product that failed listing validation to manual review
+type ListingReview struct {
+	Product     Product            `json:"product"`
+	Marketplace string             `json:"marketplace" jsonschema:"required"`
+	Violations  []ListingViolation `json:"violations"`
+	Fixed       []ListingViolation `json:"fixed,omitempty"` // auto-fixed before the remaining violations were found
+	CreatedAt   time.Time          `json:"created_at"`
 }
 
 // Order represents an order from any marketplace
 type Order struct {
-	ID           string     `json:"id"`
-	Marketplace  string     `json:"marketplace"`
-	OrderID      string     `json:"order_id"` // External order ID
-	ProductID    string     `json:"product_id"`
-	UserID       string     `json:"user_id"`
-	Quantity     int        `json:"quantity"`
-	Price        float64    `json:"price"`
-	Status       string     `json:"status"` // new, processing, shipped, delivered, cancelled
-	CustomerInfo Customer   `json:"customer_info"`
-	CreatedAt    time.Time  `json:"created_at"`
-	UpdatedAt    time.Time  `json:"updated_at"`
+	ID           string    `json:"id"`
+	Marketplace  string    `json:"marketplace" jsonschema:"required"`
+	OrderID      string    `json:"order_id" jsonschema:"required"` // External order ID
+	ProductID    string    `json:"product_id" jsonschema:"required"`
+	SKU          string    `json:"sku,omitempty"` // seller SKU ordered, a variant SKU for products with variants
+	UserID       string    `json:"user_id"`
+	Quantity     int       `json:"quantity" jsonschema:"minimum=1"`
+	Price        float64   `json:"price" jsonschema:"minimum=0"`
+	Currency     string    `json:"currency,omitempty"`
+	Region       string    `json:"region,omitempty"` // marketplace region, e.g. us, de, tr
+	Status       string    `json:"status"`           // new, processing, shipped, delivered, cancelled
+	CustomerInfo Customer  `json:"customer_info"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 // Customer represents customer information
 type Customer struct {
-	Name    string `json:"name"`
-	Email   string `json:"email"`
-	Phone   string `json:"phone"`
+	Name    string  `json:"name"`
+	Email   string  `json:"email"`
+	Phone   string  `json:"phone"`
 	Address Address `json:"address"`
 }
 
 // Address represents shipping address
 type Address struct {
-	Street   string `json:"street"`
-	City     string `json:"city"`
-	State    string `json:"state"`
-	Country  string `json:"country"`
-	ZipCode  string `json:"zip_code"`
+	Street  string `json:"street"`
+	City    string `json:"city"`
+	State   string `json:"state"`
+	Country string `json:"country"`
+	ZipCode string `json:"zip_code"`
 }
 
 // InventoryUpdate represents inventory synchronization data
 type InventoryUpdate struct {
-	ProductID   string    `json:"product_id"`
-	Marketplace string    `json:"marketplace"`
-	Stock       int       `json:"stock"`
-	Price       float64   `json:"price"`
-	UpdateType  string    `json:"update_type"` // stock, price, both
+	ProductID   string    `json:"product_id" jsonschema:"required"`
+	Marketplace string    `json:"marketplace" jsonschema:"required"`
+	Stock       int       `json:"stock" jsonschema:"minimum=0"`
+	Price       float64   `json:"price" jsonschema:"minimum=0"`
+	UpdateType  string    `json:"update_type" jsonschema:"enum=stock|price|both"` // stock, price, both
+	SKU         string    `json:"sku,omitempty"`                                  // variant SKU; empty updates the whole product
 	Timestamp   time.Time `json:"timestamp"`
+	Version     int64     `json:"version,omitempty"` // per-product sequence; newer updates have higher versions
 }
 
 // ProcessingEvent represents events in the processing pipeline
 type ProcessingEvent struct {
-	ID        string                 `json:"id"`
-	Type      string                 `json:"type"` // image_uploaded, ai_enhanced, seo_generated, listed
-	ProductID string                 `json:"product_id"`
-	Data      map[string]interface{} `json:"data"`
+	ID        string                 `json:"id" jsonschema:"required"`
+	Type      string                 `json:"type" jsonschema:"required"` // image_uploaded, ai_enhanced, seo_generated, marketplace_listed
+	ProductID string                 `json:"product_id" jsonschema:"required"`
+	Data      map[string]interface{} `json:"data"` // decode with Payload()
 	Timestamp time.Time              `json:"timestamp"`
 	Source    string                 `json:"source"` // service name
 }
@@ -0,0 +1,93 @@
+// Package orders ingests marketplace orders, by polling each marketplace's
+// order API and through signed webhooks, and publishes them to stox.orders
+// as models.Order with routing key order.<marketplace>.<region>.
+package orders
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"stox-rabbitmq/internal/models"
+)
+
+// Adapter describes one marketplace's order API and payload format
+type Adapter struct {
+	Marketplace string
+
+	// PollPath is requested as GET <api>/<PollPath>?since=<RFC3339>
+	PollPath string
+
+	// Webhook signature headers; see Sign
+	SignatureHeader string
+	TimestampHeader string
+
+	// DecodePage extracts the raw orders from a poll response
+	DecodePage func(body []byte) ([]json.RawMessage, error)
+
+	// EncodePage wraps raw orders in a poll response, for mock marketplaces
+	EncodePage func(orders []json.RawMessage) ([]byte, error)
+
+	// Normalize converts one marketplace order into one models.Order per line
+	Normalize func(raw []byte) ([]models.Order, error)
+
+	// Encode renders a single-line order in the marketplace's format, for mock marketplaces
+	Encode func(order models.Order) (json.RawMessage, error)
+}
+
+// Adapters are the supported marketplaces
+var Adapters = map[string]Adapter{
+	"amazon":      amazonAdapter,
+	"trendyol":    trendyolAdapter,
+	"hepsiburada": hepsiburadaAdapter,
+}
+
+// AdapterFor returns a marketplace's adapter
+func AdapterFor(marketplace string) (Adapter, error) {
+	adapter, ok := Adapters[marketplace]
+	if !ok {
+		return Adapter{}, fmt.Errorf("no order adapter for marketplace %q", marketplace)
+	}
+	return adapter, nil
+}
+
+// RoutingKey is the stox.orders routing key for an order
+func RoutingKey(order models.Order) string {
+	region := order.Region
+	if region == "" {
+		region = "unknown"
+	}
+	return fmt.Sprintf("order.%s.%s", order.Marketplace, region)
+}
+
+// lineID builds the Stox order ID for one line of a marketplace order
+func lineID(prefix, orderID string, line, lines int) string {
+	if lines == 1 {
+		return fmt.Sprintf("%s_order_%s", prefix, orderID)
+	}
+	return fmt.Sprintf("%s_order_%s_%d", prefix, orderID, line+1)
+}
+
+// marketplaceStatus is the marketplace's name for a Stox order status
+func marketplaceStatus(statuses map[string]string, status string) string {
+	names := make([]string, 0, len(statuses))
+	for name, s := range statuses {
+		if s == status {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 {
+		return status
+	}
+	slices.Sort(names)
+	return strings.ToUpper(names[0][:1]) + names[0][1:]
+}
+
+// mapStatus translates a marketplace order status, defaulting to new
+func mapStatus(statuses map[string]string, status string) string {
+	if s, ok := statuses[strings.ToLower(status)]; ok {
+		return s
+	}
+	return "new"
+}
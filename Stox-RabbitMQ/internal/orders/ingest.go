@@ -0,0 +1,316 @@
+package orders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/rabbitmq"
+	"stox-rabbitmq/internal/storage"
+)
+
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxRemembered bounds the order IDs kept for deduplication
+const maxRemembered = 10000
+
+// Catalog resolves seller SKUs on orders to the Stox products listed on a
+// marketplace. Each product's SKUs are kept in the store under
+// catalog/<marketplace>/, so Load rebuilds the catalog after a restart.
+type Catalog struct {
+	store  storage.Store
+	prefix string
+
+	mu   sync.RWMutex
+	skus map[string]string // SKU -> product ID
+}
+
+// catalogEntry is the stored form of a product's SKUs
+type catalogEntry struct {
+	ProductID string   `json:"product_id"`
+	SKUs      []string `json:"skus"`
+}
+
+// NewCatalog creates an empty catalog of a marketplace's products
+func NewCatalog(store storage.Store, marketplace string) *Catalog {
+	return &Catalog{
+		store:  store,
+		prefix: "catalog/" + marketplace + "/",
+		skus:   make(map[string]string),
+	}
+}
+
+// Load reads the products stored for the marketplace and returns how many there are
+func (c *Catalog) Load(ctx context.Context) (int, error) {
+	objects, err := c.store.List(ctx, c.prefix)
+	if err != nil {
+		return 0, fmt.Errorf("failed to list catalog: %w", err)
+	}
+	for _, object := range objects {
+		data, err := c.store.Get(ctx, object.Key)
+		if err != nil {
+			return 0, fmt.Errorf("failed to load catalog entry %s: %w", object.Key, err)
+		}
+		var entry catalogEntry
+		if err := json.Unmarshal(data, &entry); err != nil {
+			return 0, fmt.Errorf("corrupt catalog entry %s: %w", object.Key, err)
+		}
+		c.add(entry)
+	}
+	return len(objects), nil
+}
+
+// Add registers a product's ID and variant SKUs and stores them
+func (c *Catalog) Add(ctx context.Context, product models.Product) error {
+	entry := catalogEntry{ProductID: product.ID, SKUs: []string{product.ID}}
+	for _, v := range product.Variants {
+		entry.SKUs = append(entry.SKUs, v.SKU)
+	}
+	c.add(entry)
+
+	data, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	if err := c.store.Put(ctx, c.prefix+url.PathEscape(product.ID)+".json", data); err != nil {
+		return fmt.Errorf("failed to save catalog entry of %s: %w", product.ID, err)
+	}
+	return nil
+}
+
+func (c *Catalog) add(entry catalogEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, sku := range entry.SKUs {
+		c.skus[sku] = entry.ProductID
+	}
+}
+
+// Resolve returns the product a SKU belongs to
+func (c *Catalog) Resolve(sku string) (string, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	id, ok := c.skus[sku]
+	return id, ok
+}
+
+// Cursor is the polling position, persisted between restarts
+type Cursor struct {
+	Since time.Time `json:"since"` // created time of the newest order seen
+	IDs   []string  `json:"ids"`   // orders created at Since, which the next poll returns again
+}
+
+// Ingester normalizes one marketplace's orders and publishes them to stox.orders
+type Ingester struct {
+	adapter Adapter
+	client  *rabbitmq.Client
+	store   storage.Store // holds the polling cursor
+	catalog *Catalog
+
+	mu   sync.Mutex
+	seen map[string]bool // order line IDs already published
+	fifo []string
+}
+
+// NewIngester creates the ingester for a marketplace adapter
+func NewIngester(marketplace string, client *rabbitmq.Client, store storage.Store, catalog *Catalog) (*Ingester, error) {
+	adapter, err := AdapterFor(marketplace)
+	if err != nil {
+		return nil, err
+	}
+	return &Ingester{
+		adapter: adapter,
+		client:  client,
+		store:   store,
+		catalog: catalog,
+		seen:    make(map[string]bool),
+	}, nil
+}
+
+// Ingest normalizes one raw marketplace order and publishes each new line.
+// Orders arriving by both webhook and polling are published once.
+func (i *Ingester) Ingest(raw []byte, via string) (int, error) {
+	orders, err := i.adapter.Normalize(raw)
+	if err != nil {
+		return 0, err
+	}
+	return i.publish(orders, via)
+}
+
+// publish sends the order lines not published before
+func (i *Ingester) publish(orders []models.Order, via string) (int, error) {
+	published := 0
+	for _, order := range orders {
+		if !i.remember(order.ID) {
+			continue
+		}
+
+		productID, ok := i.catalog.Resolve(order.SKU)
+		if !ok {
+			productID = order.SKU // sellers without variants commonly use the product ID as SKU
+			log.Printf("  ⚠️  %s order %s: SKU %s is not in the catalog", i.adapter.Marketplace, order.OrderID, order.SKU)
+		}
+		order.ProductID = productID
+
+		err := i.client.PublishMessage("stox.orders", RoutingKey(order), order)
+		if err != nil {
+			i.forget(order.ID)
+			return published, fmt.Errorf("failed to publish order %s: %w", order.ID, err)
+		}
+		published++
+		log.Printf("📥 Ingested %s order %s (%s x%d) via %s -> %s",
+			i.adapter.Marketplace, order.OrderID, order.SKU, order.Quantity, via, RoutingKey(order))
+	}
+	return published, nil
+}
+
+// remember records an order line ID, reporting false if it was already seen
+func (i *Ingester) remember(id string) bool {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	if i.seen[id] {
+		return false
+	}
+	i.seen[id] = true
+	i.fifo = append(i.fifo, id)
+	if len(i.fifo) > maxRemembered {
+		delete(i.seen, i.fifo[0])
+		i.fifo = i.fifo[1:]
+	}
+	return true
+}
+
+func (i *Ingester) forget(id string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.seen, id)
+}
+
+// cursorKey is where the polling cursor is stored
+func (i *Ingester) cursorKey() string {
+	return fmt.Sprintf("cursors/orders/%s.json", i.adapter.Marketplace)
+}
+
+func (i *Ingester) loadCursor(ctx context.Context) (Cursor, error) {
+	var cursor Cursor
+	data, err := i.store.Get(ctx, i.cursorKey())
+	if errors.Is(err, storage.ErrNotFound) {
+		return cursor, nil
+	}
+	if err != nil {
+		return cursor, err
+	}
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return cursor, fmt.Errorf("corrupt order cursor %s: %w", i.cursorKey(), err)
+	}
+	return cursor, nil
+}
+
+func (i *Ingester) saveCursor(ctx context.Context, cursor Cursor) error {
+	data, err := json.Marshal(cursor)
+	if err != nil {
+		return err
+	}
+	return i.store.Put(ctx, i.cursorKey(), data)
+}
+
+// Poll fetches new orders from the marketplace API at apiURL every interval
+// until ctx is cancelled. The cursor only advances past orders that were published.
+func (i *Ingester) Poll(ctx context.Context, apiURL string, interval time.Duration) {
+	cursor, err := i.loadCursor(ctx)
+	if err != nil {
+		log.Printf("⚠️  Failed to load %s order cursor, starting from now: %v", i.adapter.Marketplace, err)
+		cursor = Cursor{Since: time.Now()}
+	}
+	for _, id := range cursor.IDs {
+		i.remember(id)
+	}
+	log.Printf("📡 Polling %s orders from %s every %s (since %s)",
+		i.adapter.Marketplace, apiURL, interval, cursor.Since.Format(time.RFC3339))
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		next, err := i.pollOnce(ctx, apiURL, cursor)
+		if err != nil {
+			log.Printf("⚠️  %s order poll failed: %v", i.adapter.Marketplace, err)
+		}
+		if next.Since.After(cursor.Since) || len(next.IDs) != len(cursor.IDs) {
+			if err := i.saveCursor(ctx, next); err != nil {
+				log.Printf("⚠️  Failed to save %s order cursor: %v", i.adapter.Marketplace, err)
+			}
+			cursor = next
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// pollOnce fetches one page of orders and returns the advanced cursor
+func (i *Ingester) pollOnce(ctx context.Context, apiURL string, cursor Cursor) (Cursor, error) {
+	u := strings.TrimSuffix(apiURL, "/") + "/" + i.adapter.PollPath + "?since=" + url.QueryEscape(cursor.Since.UTC().Format(time.RFC3339Nano))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return cursor, err
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return cursor, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return cursor, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return cursor, fmt.Errorf("GET %s: %s", u, resp.Status)
+	}
+
+	raws, err := i.adapter.DecodePage(body)
+	if err != nil {
+		return cursor, fmt.Errorf("failed to decode %s order page: %w", i.adapter.Marketplace, err)
+	}
+
+	next := cursor
+	for _, raw := range raws {
+		orders, err := i.adapter.Normalize(raw)
+		if err != nil {
+			log.Printf("⚠️  Skipping invalid %s order: %v", i.adapter.Marketplace, err)
+			continue
+		}
+		if _, err := i.publish(orders, "polling"); err != nil {
+			return next, err
+		}
+
+		for _, order := range orders {
+			switch {
+			case order.CreatedAt.After(next.Since):
+				next.Since = order.CreatedAt
+				next.IDs = []string{order.ID}
+			case order.CreatedAt.Equal(next.Since) && !slices.Contains(next.IDs, order.ID):
+				next.IDs = append(next.IDs, order.ID)
+			}
+		}
+	}
+	return next, nil
+}
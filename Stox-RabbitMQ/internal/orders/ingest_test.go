@@ -0,0 +1,61 @@
+package orders
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"stox-rabbitmq/internal/models"
+	"stox-rabbitmq/internal/storage"
+)
+
+func TestCatalogSurvivesRestart(t *testing.T) {
+	ctx := context.Background()
+	store, err := storage.NewFileStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	catalog := NewCatalog(store, "trendyol")
+	err = catalog.Add(ctx, models.Product{
+		ID:       "prod_1",
+		Variants: []models.Variant{{SKU: "FP-W-BLK-45"}, {SKU: "FP-W-RED-45"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	restarted := NewCatalog(store, "trendyol")
+	if n, err := restarted.Load(ctx); err != nil || n != 1 {
+		t.Fatalf("loaded %d products (%v), want 1", n, err)
+	}
+	for _, sku := range []string{"prod_1", "FP-W-BLK-45", "FP-W-RED-45"} {
+		if id, ok := restarted.Resolve(sku); !ok || id != "prod_1" {
+			t.Errorf("%s resolved to %q, %v", sku, id, ok)
+		}
+	}
+
+	other := NewCatalog(store, "amazon")
+	if n, _ := other.Load(ctx); n != 0 {
+		t.Errorf("amazon loaded %d of trendyol's products", n)
+	}
+}
+
+func TestWebhookWithoutSecretRefusesOrders(t *testing.T) {
+	ingester, err := NewIngester("amazon", nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"OrderId":"AMZ-1"}`
+	req := httptest.NewRequest(http.MethodPost, WebhookPath("amazon"), strings.NewReader(body))
+	req.Header.Set(amazonAdapter.TimestampHeader, "0")
+	req.Header.Set(amazonAdapter.SignatureHeader, "sha256="+Sign("", 0, []byte(body)))
+
+	rec := httptest.NewRecorder()
+	ingester.Webhook("").ServeHTTP(rec, req)
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("got %d, want %d", rec.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,404 @@
+package orders
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"stox-rabbitmq/internal/models"
+)
+
+// Amazon orders follow the Selling Partner API shape
+
+type amazonMoney struct {
+	Amount       string `json:"Amount"`
+	CurrencyCode string `json:"CurrencyCode"`
+}
+
+type amazonOrder struct {
+	AmazonOrderID string    `json:"AmazonOrderId"`
+	PurchaseDate  time.Time `json:"PurchaseDate"`
+	OrderStatus   string    `json:"OrderStatus"`
+	MarketplaceID string    `json:"MarketplaceId"`
+	BuyerInfo     struct {
+		BuyerEmail string `json:"BuyerEmail"`
+	} `json:"BuyerInfo"`
+	ShippingAddress struct {
+		Name          string `json:"Name"`
+		AddressLine1  string `json:"AddressLine1"`
+		City          string `json:"City"`
+		StateOrRegion string `json:"StateOrRegion"`
+		PostalCode    string `json:"PostalCode"`
+		CountryCode   string `json:"CountryCode"`
+		Phone         string `json:"Phone"`
+	} `json:"ShippingAddress"`
+	OrderItems []amazonOrderItem `json:"OrderItems"`
+}
+
+type amazonOrderItem struct {
+	SellerSKU       string      `json:"SellerSKU"`
+	QuantityOrdered int         `json:"QuantityOrdered"`
+	ItemPrice       amazonMoney `json:"ItemPrice"` // total for the line
+}
+
+// amazonRegions maps Amazon marketplace IDs to regions
+var amazonRegions = map[string]string{
+	"ATVPDKIKX0DER":  "us",
+	"A1PA6795UKMFR9": "de",
+	"A1F83G8C2ARO7P": "uk",
+	"A33AVAJ2PDY3EV": "tr",
+}
+
+var amazonStatuses = map[string]string{
+	"pending":   "new",
+	"unshipped": "new",
+	"shipped":   "shipped",
+	"canceled":  "cancelled",
+}
+
+var amazonAdapter = Adapter{
+	Marketplace:     "amazon",
+	PollPath:        "orders/v0/orders",
+	SignatureHeader: "X-Amzn-Signature",
+	TimestampHeader: "X-Amzn-Timestamp",
+	DecodePage: func(body []byte) ([]json.RawMessage, error) {
+		var page struct {
+			Payload struct {
+				Orders []json.RawMessage `json:"Orders"`
+			} `json:"payload"`
+		}
+		err := json.Unmarshal(body, &page)
+		return page.Payload.Orders, err
+	},
+	EncodePage: func(orders []json.RawMessage) ([]byte, error) {
+		var page struct {
+			Payload struct {
+				Orders []json.RawMessage `json:"Orders"`
+			} `json:"payload"`
+		}
+		page.Payload.Orders = orders
+		return json.Marshal(page)
+	},
+	Normalize: func(raw []byte) ([]models.Order, error) {
+		var o amazonOrder
+		if err := json.Unmarshal(raw, &o); err != nil {
+			return nil, fmt.Errorf("invalid amazon order: %w", err)
+		}
+		if o.AmazonOrderID == "" || len(o.OrderItems) == 0 {
+			return nil, fmt.Errorf("amazon order needs AmazonOrderId and OrderItems")
+		}
+
+		region := amazonRegions[o.MarketplaceID]
+		if region == "" {
+			region = strings.ToLower(o.ShippingAddress.CountryCode)
+		}
+		customer := models.Customer{
+			Name:  o.ShippingAddress.Name,
+			Email: o.BuyerInfo.BuyerEmail,
+			Phone: o.ShippingAddress.Phone,
+			Address: models.Address{
+				Street:  o.ShippingAddress.AddressLine1,
+				City:    o.ShippingAddress.City,
+				State:   o.ShippingAddress.StateOrRegion,
+				Country: o.ShippingAddress.CountryCode,
+				ZipCode: o.ShippingAddress.PostalCode,
+			},
+		}
+
+		var out []models.Order
+		for i, item := range o.OrderItems {
+			total, err := strconv.ParseFloat(item.ItemPrice.Amount, 64)
+			if err != nil {
+				return nil, fmt.Errorf("amazon order %s: invalid price %q", o.AmazonOrderID, item.ItemPrice.Amount)
+			}
+			price := total
+			if item.QuantityOrdered > 0 {
+				price = total / float64(item.QuantityOrdered)
+			}
+			out = append(out, models.Order{
+				ID:           lineID("amz", o.AmazonOrderID, i, len(o.OrderItems)),
+				Marketplace:  "amazon",
+				OrderID:      o.AmazonOrderID,
+				SKU:          item.SellerSKU,
+				Quantity:     item.QuantityOrdered,
+				Price:        price,
+				Currency:     item.ItemPrice.CurrencyCode,
+				Region:       region,
+				Status:       mapStatus(amazonStatuses, o.OrderStatus),
+				CustomerInfo: customer,
+				CreatedAt:    o.PurchaseDate,
+				UpdatedAt:    time.Now(),
+			})
+		}
+		return out, nil
+	},
+	Encode: func(order models.Order) (json.RawMessage, error) {
+		var o amazonOrder
+		o.AmazonOrderID = order.OrderID
+		o.PurchaseDate = order.CreatedAt.UTC()
+		o.OrderStatus = marketplaceStatus(amazonStatuses, order.Status)
+		for id, region := range amazonRegions {
+			if region == order.Region {
+				o.MarketplaceID = id
+			}
+		}
+		o.BuyerInfo.BuyerEmail = order.CustomerInfo.Email
+		o.ShippingAddress.Name = order.CustomerInfo.Name
+		o.ShippingAddress.AddressLine1 = order.CustomerInfo.Address.Street
+		o.ShippingAddress.City = order.CustomerInfo.Address.City
+		o.ShippingAddress.StateOrRegion = order.CustomerInfo.Address.State
+		o.ShippingAddress.PostalCode = order.CustomerInfo.Address.ZipCode
+		o.ShippingAddress.CountryCode = order.CustomerInfo.Address.Country
+		o.ShippingAddress.Phone = order.CustomerInfo.Phone
+		o.OrderItems = []amazonOrderItem{{
+			SellerSKU:       order.SKU,
+			QuantityOrdered: order.Quantity,
+			ItemPrice: amazonMoney{
+				Amount:       strconv.FormatFloat(order.Price*float64(order.Quantity), 'f', 2, 64),
+				CurrencyCode: order.Currency,
+			},
+		}}
+		return json.Marshal(o)
+	},
+}
+
+// Trendyol orders follow the supplier order API shape (dates in epoch milliseconds)
+
+type trendyolOrder struct {
+	OrderNumber       string `json:"orderNumber"`
+	OrderDate         int64  `json:"orderDate"`
+	Status            string `json:"status"`
+	CustomerFirstName string `json:"customerFirstName"`
+	CustomerLastName  string `json:"customerLastName"`
+	CustomerEmail     string `json:"customerEmail"`
+	ShipmentAddress   struct {
+		Address1    string `json:"address1"`
+		City        string `json:"city"`
+		District    string `json:"district"`
+		PostalCode  string `json:"postalCode"`
+		CountryCode string `json:"countryCode"`
+		Phone       string `json:"phone"`
+	} `json:"shipmentAddress"`
+	CurrencyCode string         `json:"currencyCode"`
+	Lines        []trendyolLine `json:"lines"`
+}
+
+type trendyolLine struct {
+	MerchantSKU string  `json:"merchantSku"`
+	Quantity    int     `json:"quantity"`
+	Price       float64 `json:"price"` // unit price
+}
+
+var trendyolStatuses = map[string]string{
+	"created":   "new",
+	"picking":   "processing",
+	"invoiced":  "processing",
+	"shipped":   "shipped",
+	"delivered": "delivered",
+	"cancelled": "cancelled",
+}
+
+var trendyolAdapter = Adapter{
+	Marketplace:     "trendyol",
+	PollPath:        "suppliers/orders",
+	SignatureHeader: "X-Trendyol-Signature",
+	TimestampHeader: "X-Trendyol-Timestamp",
+	DecodePage: func(body []byte) ([]json.RawMessage, error) {
+		var page struct {
+			Content []json.RawMessage `json:"content"`
+		}
+		err := json.Unmarshal(body, &page)
+		return page.Content, err
+	},
+	EncodePage: func(orders []json.RawMessage) ([]byte, error) {
+		return json.Marshal(map[string][]json.RawMessage{"content": orders})
+	},
+	Normalize: func(raw []byte) ([]models.Order, error) {
+		var o trendyolOrder
+		if err := json.Unmarshal(raw, &o); err != nil {
+			return nil, fmt.Errorf("invalid trendyol order: %w", err)
+		}
+		if o.OrderNumber == "" || len(o.Lines) == 0 {
+			return nil, fmt.Errorf("trendyol order needs orderNumber and lines")
+		}
+
+		region := strings.ToLower(o.ShipmentAddress.CountryCode)
+		if region == "" {
+			region = "tr"
+		}
+		currency := o.CurrencyCode
+		if currency == "" {
+			currency = "TRY"
+		}
+		customer := models.Customer{
+			Name:  strings.TrimSpace(o.CustomerFirstName + " " + o.CustomerLastName),
+			Email: o.CustomerEmail,
+			Phone: o.ShipmentAddress.Phone,
+			Address: models.Address{
+				Street:  o.ShipmentAddress.Address1,
+				City:    o.ShipmentAddress.City,
+				State:   o.ShipmentAddress.District,
+				Country: o.ShipmentAddress.CountryCode,
+				ZipCode: o.ShipmentAddress.PostalCode,
+			},
+		}
+
+		var out []models.Order
+		for i, line := range o.Lines {
+			out = append(out, models.Order{
+				ID:           lineID("tdy", o.OrderNumber, i, len(o.Lines)),
+				Marketplace:  "trendyol",
+				OrderID:      o.OrderNumber,
+				SKU:          line.MerchantSKU,
+				Quantity:     line.Quantity,
+				Price:        line.Price,
+				Currency:     currency,
+				Region:       region,
+				Status:       mapStatus(trendyolStatuses, o.Status),
+				CustomerInfo: customer,
+				CreatedAt:    time.UnixMilli(o.OrderDate),
+				UpdatedAt:    time.Now(),
+			})
+		}
+		return out, nil
+	},
+	Encode: func(order models.Order) (json.RawMessage, error) {
+		var o trendyolOrder
+		o.OrderNumber = order.OrderID
+		o.OrderDate = order.CreatedAt.UnixMilli()
+		o.Status = marketplaceStatus(trendyolStatuses, order.Status)
+		first, last, _ := strings.Cut(order.CustomerInfo.Name, " ")
+		o.CustomerFirstName, o.CustomerLastName = first, last
+		o.CustomerEmail = order.CustomerInfo.Email
+		o.ShipmentAddress.Address1 = order.CustomerInfo.Address.Street
+		o.ShipmentAddress.City = order.CustomerInfo.Address.City
+		o.ShipmentAddress.District = order.CustomerInfo.Address.State
+		o.ShipmentAddress.PostalCode = order.CustomerInfo.Address.ZipCode
+		o.ShipmentAddress.CountryCode = strings.ToUpper(order.Region)
+		o.ShipmentAddress.Phone = order.CustomerInfo.Phone
+		o.CurrencyCode = order.Currency
+		o.Lines = []trendyolLine{{MerchantSKU: order.SKU, Quantity: order.Quantity, Price: order.Price}}
+		return json.Marshal(o)
+	},
+}
+
+// Hepsiburada orders follow the merchant order API shape
+
+type hepsiburadaOrder struct {
+	OrderNumber string    `json:"orderNumber"`
+	OrderDate   time.Time `json:"orderDate"`
+	Status      string    `json:"status"`
+	Customer    struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	} `json:"customer"`
+	DeliveryAddress struct {
+		Address     string `json:"address"`
+		Town        string `json:"town"`
+		City        string `json:"city"`
+		CountryCode string `json:"countryCode"`
+		PhoneNumber string `json:"phoneNumber"`
+		PostalCode  string `json:"postalCode"`
+	} `json:"deliveryAddress"`
+	Items []hepsiburadaItem `json:"items"`
+}
+
+type hepsiburadaItem struct {
+	MerchantSKU string `json:"merchantSku"`
+	Quantity    int    `json:"quantity"`
+	UnitPrice   struct {
+		Amount   float64 `json:"amount"`
+		Currency string  `json:"currency"`
+	} `json:"unitPrice"`
+}
+
+var hepsiburadaStatuses = map[string]string{
+	"open":      "new",
+	"packed":    "processing",
+	"intransit": "shipped",
+	"delivered": "delivered",
+	"cancelled": "cancelled",
+}
+
+var hepsiburadaAdapter = Adapter{
+	Marketplace:     "hepsiburada",
+	PollPath:        "orders/merchantid",
+	SignatureHeader: "X-Hb-Signature",
+	TimestampHeader: "X-Hb-Timestamp",
+	DecodePage: func(body []byte) ([]json.RawMessage, error) {
+		var page struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		err := json.Unmarshal(body, &page)
+		return page.Items, err
+	},
+	EncodePage: func(orders []json.RawMessage) ([]byte, error) {
+		return json.Marshal(map[string][]json.RawMessage{"items": orders})
+	},
+	Normalize: func(raw []byte) ([]models.Order, error) {
+		var o hepsiburadaOrder
+		if err := json.Unmarshal(raw, &o); err != nil {
+			return nil, fmt.Errorf("invalid hepsiburada order: %w", err)
+		}
+		if o.OrderNumber == "" || len(o.Items) == 0 {
+			return nil, fmt.Errorf("hepsiburada order needs orderNumber and items")
+		}
+
+		region := strings.ToLower(o.DeliveryAddress.CountryCode)
+		if region == "" {
+			region = "tr"
+		}
+		customer := models.Customer{
+			Name:  o.Customer.Name,
+			Email: o.Customer.Email,
+			Phone: o.DeliveryAddress.PhoneNumber,
+			Address: models.Address{
+				Street:  o.DeliveryAddress.Address,
+				City:    o.DeliveryAddress.City,
+				State:   o.DeliveryAddress.Town,
+				Country: o.DeliveryAddress.CountryCode,
+				ZipCode: o.DeliveryAddress.PostalCode,
+			},
+		}
+
+		var out []models.Order
+		for i, item := range o.Items {
+			out = append(out, models.Order{
+				ID:           lineID("hb", o.OrderNumber, i, len(o.Items)),
+				Marketplace:  "hepsiburada",
+				OrderID:      o.OrderNumber,
+				SKU:          item.MerchantSKU,
+				Quantity:     item.Quantity,
+				Price:        item.UnitPrice.Amount,
+				Currency:     item.UnitPrice.Currency,
+				Region:       region,
+				Status:       mapStatus(hepsiburadaStatuses, o.Status),
+				CustomerInfo: customer,
+				CreatedAt:    o.OrderDate,
+				UpdatedAt:    time.Now(),
+			})
+		}
+		return out, nil
+	},
+	Encode: func(order models.Order) (json.RawMessage, error) {
+		var o hepsiburadaOrder
+		o.OrderNumber = order.OrderID
+		o.OrderDate = order.CreatedAt.UTC()
+		o.Status = marketplaceStatus(hepsiburadaStatuses, order.Status)
+		o.Customer.Name = order.CustomerInfo.Name
+		o.Customer.Email = order.CustomerInfo.Email
+		o.DeliveryAddress.Address = order.CustomerInfo.Address.Street
+		o.DeliveryAddress.Town = order.CustomerInfo.Address.State
+		o.DeliveryAddress.City = order.CustomerInfo.Address.City
+		o.DeliveryAddress.CountryCode = strings.ToUpper(order.Region)
+		o.DeliveryAddress.PhoneNumber = order.CustomerInfo.Phone
+		o.DeliveryAddress.PostalCode = order.CustomerInfo.Address.ZipCode
+		item := hepsiburadaItem{MerchantSKU: order.SKU, Quantity: order.Quantity}
+		item.UnitPrice.Amount = order.Price
+		item.UnitPrice.Currency = order.Currency
+		o.Items = []hepsiburadaItem{item}
+		return json.Marshal(o)
+	},
+}
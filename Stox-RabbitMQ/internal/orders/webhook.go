@@ -0,0 +1,151 @@
+package orders
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// SignatureTolerance is how far a webhook timestamp may be from now
+const SignatureTolerance = 5 * time.Minute
+
+// maxWebhookBody caps webhook request bodies
+const maxWebhookBody = 1 << 20
+
+var (
+	ErrMissingSignature = errors.New("missing webhook signature")
+	ErrBadSignature     = errors.New("webhook signature does not match")
+	ErrStaleSignature   = errors.New("webhook timestamp outside tolerance")
+)
+
+// Sign computes a webhook signature: hex HMAC-SHA256 of "<timestamp>.<body>"
+// keyed with the shared secret, where timestamp is in Unix seconds
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify checks a webhook signature and that its timestamp is recent
+func Verify(secret, signature, timestamp string, body []byte, now time.Time) error {
+	signature = strings.TrimPrefix(signature, "sha256=")
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid timestamp %q", ErrBadSignature, timestamp)
+	}
+	if d := now.Sub(time.Unix(ts, 0)); d > SignatureTolerance || d < -SignatureTolerance {
+		return ErrStaleSignature
+	}
+
+	want, err := hex.DecodeString(Sign(secret, ts, body))
+	if err != nil {
+		return err
+	}
+	got, err := hex.DecodeString(signature)
+	if err != nil || !hmac.Equal(got, want) {
+		return ErrBadSignature
+	}
+	return nil
+}
+
+// WebhookPath is where a marketplace's order webhook is served
+func WebhookPath(marketplace string) string {
+	return fmt.Sprintf("/webhooks/%s/orders", marketplace)
+}
+
+// Webhook receives order notifications signed with secret. The body is one
+// marketplace order in the adapter's format. Without a secret every
+// notification is refused.
+func (i *Ingester) Webhook(secret string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if secret == "" {
+			http.Error(w, "order webhooks are not configured", http.StatusServiceUnavailable)
+			return
+		}
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxWebhookBody+1))
+		if err != nil {
+			http.Error(w, "failed to read body", http.StatusBadRequest)
+			return
+		}
+		if len(body) > maxWebhookBody {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		err = Verify(secret, r.Header.Get(i.adapter.SignatureHeader), r.Header.Get(i.adapter.TimestampHeader), body, time.Now())
+		if err != nil {
+			log.Printf("🚫 Rejected %s order webhook from %s: %v", i.adapter.Marketplace, r.RemoteAddr, err)
+			http.Error(w, err.Error(), http.StatusUnauthorized)
+			return
+		}
+
+		n, err := i.Ingest(body, "webhook")
+		if err != nil {
+			log.Printf("⚠️  %s order webhook failed: %v", i.adapter.Marketplace, err)
+			http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+			return
+		}
+
+		w.WriteHeader(http.StatusAccepted)
+		fmt.Fprintf(w, "{\"accepted\":%d}\n", n)
+	})
+}
+
+// Deliver posts a signed order webhook, as a marketplace would
+func Deliver(ctx context.Context, url, secret string, adapter Adapter, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(adapter.TimestampHeader, strconv.FormatInt(ts, 10))
+	req.Header.Set(adapter.SignatureHeader, "sha256="+Sign(secret, ts, body))
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to deliver webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusAccepted {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("webhook rejected: %s: %s", resp.Status, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// LocalURL is the webhook URL of a service listening on addr, for simulators
+// running in the same process
+func LocalURL(addr, marketplace string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "http://" + addr + WebhookPath(marketplace)
+	}
+	if host == "" || host == "0.0.0.0" || host == "::" {
+		host = "localhost"
+	}
+	return "http://" + net.JoinHostPort(host, port) + WebhookPath(marketplace)
+}
@@ -0,0 +1,107 @@
+// Package outbox implements the transactional outbox pattern for Stox services.
+//
+// Services write the events they want to publish into the outbox table inside
+// the same database transaction that changes their state. A Relay then reads
+// unsent rows, publishes them with publisher confirms and marks them sent, so
+// a crash between "commit" and "publish" can no longer lose an event.
+//
+// The SQL is PostgreSQL's ($n placeholders, BIGSERIAL, a partial index). The
+// caller opens the database with a PostgreSQL driver, e.g. pgx's stdlib
+// package, and runs Migrate at startup; this module imports no driver until a
+// service keeps its state in a database.
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Schema creates the outbox table (PostgreSQL dialect)
+const Schema = `
+CREATE TABLE IF NOT EXISTS outbox (
+	id           BIGSERIAL PRIMARY KEY,
+	aggregate_id TEXT        NOT NULL,
+	exchange     TEXT        NOT NULL,
+	routing_key  TEXT        NOT NULL,
+	payload      BYTEA       NOT NULL,
+	created_at   TIMESTAMPTZ NOT NULL DEFAULT now(),
+	sent_at      TIMESTAMPTZ
+);
+CREATE INDEX IF NOT EXISTS outbox_unsent_idx ON outbox (id) WHERE sent_at IS NULL;
+`
+
+// Message is a single event stored in the outbox
+type Message struct {
+	ID          int64
+	AggregateID string // Product ID; messages for one aggregate are published in order
+	Exchange    string
+	RoutingKey  string
+	Payload     []byte
+	CreatedAt   time.Time
+}
+
+// Migrate creates the outbox table if it does not exist yet
+func Migrate(ctx context.Context, db *sql.DB) error {
+	if _, err := db.ExecContext(ctx, Schema); err != nil {
+		return fmt.Errorf("failed to create outbox table: %w", err)
+	}
+	return nil
+}
+
+// Enqueue stores a message in the outbox as part of the caller's transaction.
+// The message is JSON encoded exactly like rabbitmq.Client.PublishMessage would.
+func Enqueue(ctx context.Context, tx *sql.Tx, aggregateID, exchange, routingKey string, message interface{}) error {
+	payload, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to marshal outbox message: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO outbox (aggregate_id, exchange, routing_key, payload) VALUES ($1, $2, $3, $4)`,
+		aggregateID, exchange, routingKey, payload,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to insert outbox message: %w", err)
+	}
+
+	return nil
+}
+
+// pending loads up to limit unsent messages in insertion order
+func pending(ctx context.Context, db *sql.DB, limit int) ([]Message, error) {
+	rows, err := db.QueryContext(ctx,
+		`SELECT id, aggregate_id, exchange, routing_key, payload, created_at
+		   FROM outbox
+		  WHERE sent_at IS NULL
+		  ORDER BY id
+		  LIMIT $1`,
+		limit,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []Message
+	for rows.Next() {
+		var m Message
+		if err := rows.Scan(&m.ID, &m.AggregateID, &m.Exchange, &m.RoutingKey, &m.Payload, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan outbox row: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	return messages, rows.Err()
+}
+
+// markSent flags a message as published
+func markSent(ctx context.Context, db *sql.DB, id int64) error {
+	_, err := db.ExecContext(ctx, `UPDATE outbox SET sent_at = now() WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message %d as sent: %w", id, err)
+	}
+	return nil
+}
@@ -0,0 +1,282 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// The outbox SQL is PostgreSQL's. These tests run it through an in-memory
+// database/sql driver that understands the outbox's statements, so Enqueue,
+// Migrate and the relay are exercised without a database server.
+
+func TestEnqueueCommitsWithTheTransaction(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	publisher := &testPublisher{}
+	relay := NewRelay(db, publisher, RelayConfig{})
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Enqueue(ctx, tx, "prod_1", "stox.listings", "event.listed", map[string]string{"status": "rolled back"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Rollback(); err != nil {
+		t.Fatal(err)
+	}
+
+	tx, err = db.BeginTx(ctx, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Enqueue(ctx, tx, "prod_1", "stox.listings", "event.listed", map[string]string{"status": "listed"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+
+	sent, err := relay.RelayOnce(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sent != 1 || len(publisher.published) != 1 {
+		t.Fatalf("sent %d, published %v; want only the committed message", sent, publisher.published)
+	}
+	if got, want := publisher.published[0], `stox.listings/event.listed {"status":"listed"}`; got != want {
+		t.Errorf("published %s, want %s", got, want)
+	}
+
+	if sent, _ := relay.RelayOnce(ctx); sent != 0 {
+		t.Errorf("sent messages were published again (%d)", sent)
+	}
+}
+
+func TestRelayHoldsBackAggregateAfterFailure(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	publisher := &testPublisher{fail: map[string]int{`{"n":1}`: 1}}
+	relay := NewRelay(db, publisher, RelayConfig{})
+
+	enqueue := func(aggregateID string, n int) {
+		tx, err := db.BeginTx(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := Enqueue(ctx, tx, aggregateID, "stox.sync", "amazon_sync.0", map[string]int{"n": n}); err != nil {
+			t.Fatal(err)
+		}
+		if err := tx.Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	enqueue("prod_1", 1)
+	enqueue("prod_2", 2)
+	enqueue("prod_1", 3)
+
+	// prod_1's first message fails, so its second waits; prod_2 flows
+	if sent, err := relay.RelayOnce(ctx); err != nil || sent != 1 {
+		t.Fatalf("first round sent %d (%v), want 1", sent, err)
+	}
+	if sent, err := relay.RelayOnce(ctx); err != nil || sent != 2 {
+		t.Fatalf("second round sent %d (%v), want 2", sent, err)
+	}
+
+	want := []string{
+		`stox.sync/amazon_sync.0 {"n":2}`,
+		`stox.sync/amazon_sync.0 {"n":1}`,
+		`stox.sync/amazon_sync.0 {"n":3}`,
+	}
+	if strings.Join(publisher.published, "\n") != strings.Join(want, "\n") {
+		t.Errorf("published\n%s\nwant\n%s", strings.Join(publisher.published, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+// testPublisher records confirmed publishes; fail makes a payload fail that many times
+type testPublisher struct {
+	fail      map[string]int
+	published []string
+}
+
+func (p *testPublisher) PublishConfirmed(ctx context.Context, exchange, routingKey string, body []byte) error {
+	if p.fail[string(body)] > 0 {
+		p.fail[string(body)]--
+		return errors.New("nack")
+	}
+	p.published = append(p.published, fmt.Sprintf("%s/%s %s", exchange, routingKey, body))
+	return nil
+}
+
+// openTestDB opens an empty in-memory outbox and migrates it
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := sql.Open("outboxtest", t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	if err := Migrate(context.Background(), db); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func init() {
+	sql.Register("outboxtest", &testDriver{dbs: make(map[string]*testDB)})
+}
+
+// testDriver serves one in-memory outbox table per data source name
+type testDriver struct {
+	mu  sync.Mutex
+	dbs map[string]*testDB
+}
+
+func (d *testDriver) Open(name string) (driver.Conn, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if d.dbs[name] == nil {
+		d.dbs[name] = &testDB{}
+	}
+	return &testConn{db: d.dbs[name]}, nil
+}
+
+type testRow struct {
+	id                                int64
+	aggregateID, exchange, routingKey string
+	payload                           []byte
+	createdAt                         time.Time
+	sent                              bool
+}
+
+type testDB struct {
+	mu       sync.Mutex
+	migrated bool
+	rows     []*testRow
+	nextID   int64
+}
+
+// testConn executes the outbox's statements; inserts in a transaction are
+// applied on commit
+type testConn struct {
+	db      *testDB
+	pending []*testRow
+	inTx    bool
+}
+
+func (c *testConn) Prepare(query string) (driver.Stmt, error) {
+	return &testStmt{conn: c, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (c *testConn) Close() error { return nil }
+
+func (c *testConn) Begin() (driver.Tx, error) {
+	c.inTx, c.pending = true, nil
+	return c, nil
+}
+
+func (c *testConn) Commit() error {
+	c.db.mu.Lock()
+	defer c.db.mu.Unlock()
+	for _, row := range c.pending {
+		c.db.nextID++
+		row.id = c.db.nextID
+		c.db.rows = append(c.db.rows, row)
+	}
+	c.inTx, c.pending = false, nil
+	return nil
+}
+
+func (c *testConn) Rollback() error {
+	c.inTx, c.pending = false, nil
+	return nil
+}
+
+type testStmt struct {
+	conn  *testConn
+	query string
+}
+
+func (s *testStmt) Close() error  { return nil }
+func (s *testStmt) NumInput() int { return -1 }
+
+func (s *testStmt) Exec(args []driver.Value) (driver.Result, error) {
+	db := s.conn.db
+	switch {
+	case strings.HasPrefix(s.query, "CREATE TABLE IF NOT EXISTS outbox"):
+		db.mu.Lock()
+		db.migrated = true
+		db.mu.Unlock()
+	case strings.HasPrefix(s.query, "INSERT INTO outbox (aggregate_id, exchange, routing_key, payload) VALUES ($1, $2, $3, $4)"):
+		if !s.conn.inTx {
+			return nil, errors.New("outbox rows must be inserted in a transaction")
+		}
+		s.conn.pending = append(s.conn.pending, &testRow{
+			aggregateID: args[0].(string),
+			exchange:    args[1].(string),
+			routingKey:  args[2].(string),
+			payload:     args[3].([]byte),
+			createdAt:   time.Now(),
+		})
+	case s.query == "UPDATE outbox SET sent_at = now() WHERE id = $1":
+		db.mu.Lock()
+		defer db.mu.Unlock()
+		for _, row := range db.rows {
+			if row.id == args[0].(int64) {
+				row.sent = true
+			}
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", s.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (s *testStmt) Query(args []driver.Value) (driver.Rows, error) {
+	if !strings.HasPrefix(s.query, "SELECT id, aggregate_id, exchange, routing_key, payload, created_at FROM outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1") {
+		return nil, fmt.Errorf("unexpected query %q", s.query)
+	}
+
+	db := s.conn.db
+	db.mu.Lock()
+	defer db.mu.Unlock()
+	if !db.migrated {
+		return nil, errors.New(`relation "outbox" does not exist`)
+	}
+
+	var unsent []testRow
+	for _, row := range db.rows {
+		if !row.sent && int64(len(unsent)) < args[0].(int64) {
+			unsent = append(unsent, *row)
+		}
+	}
+	return &testRows{rows: unsent}, nil
+}
+
+type testRows struct {
+	rows []testRow
+}
+
+func (r *testRows) Columns() []string {
+	return []string{"id", "aggregate_id", "exchange", "routing_key", "payload", "created_at"}
+}
+
+func (r *testRows) Close() error { return nil }
+
+func (r *testRows) Next(dest []driver.Value) error {
+	if len(r.rows) == 0 {
+		return io.EOF
+	}
+	row := r.rows[0]
+	r.rows = r.rows[1:]
+	dest[0], dest[1], dest[2], dest[3], dest[4], dest[5] = row.id, row.aggregateID, row.exchange, row.routingKey, row.payload, row.createdAt
+	return nil
+}
@@ -0,0 +1,109 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"log"
+	"time"
+)
+
+// Publisher publishes a pre-encoded message and returns once the broker confirmed it.
+// rabbitmq.Client satisfies this through PublishConfirmed.
+type Publisher interface {
+	PublishConfirmed(ctx context.Context, exchange, routingKey string, body []byte) error
+}
+
+// RelayConfig controls how often and how much the relay reads from the outbox
+type RelayConfig struct {
+	PollInterval time.Duration
+	BatchSize    int
+}
+
+// Relay moves messages from the outbox table to RabbitMQ.
+// Run a single relay per outbox table: ordering per aggregate relies on it.
+type Relay struct {
+	db        *sql.DB
+	publisher Publisher
+	config    RelayConfig
+}
+
+// NewRelay creates a relay with sensible defaults for empty config values
+func NewRelay(db *sql.DB, publisher Publisher, config RelayConfig) *Relay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+
+	return &Relay{
+		db:        db,
+		publisher: publisher,
+		config:    config,
+	}
+}
+
+// Run polls the outbox until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) error {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	log.Printf("📤 Outbox relay started (poll every %v)", r.config.PollInterval)
+
+	for {
+		if _, err := r.RelayOnce(ctx); err != nil {
+			log.Printf("❌ Outbox relay error: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Println("📤 Outbox relay stopped")
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce publishes one batch of pending messages and returns how many were sent.
+//
+// Messages are handled in id order. When a publish fails, every later message of
+// the same aggregate is held back until the next round so that consumers never
+// see events for a product out of order; other aggregates keep flowing.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	messages, err := pending(ctx, r.db, r.config.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	blocked := make(map[string]bool)
+	sent := 0
+
+	for _, m := range messages {
+		if blocked[m.AggregateID] {
+			continue
+		}
+
+		err := r.publisher.PublishConfirmed(ctx, m.Exchange, m.RoutingKey, m.Payload)
+		if err != nil {
+			log.Printf("⚠️  Outbox message %d for %s not published: %v", m.ID, m.AggregateID, err)
+			blocked[m.AggregateID] = true
+			continue
+		}
+
+		if err := markSent(ctx, r.db, m.ID); err != nil {
+			// The message went out but is still unsent in the table; it will be
+			// published again, so consumers must tolerate duplicates.
+			log.Printf("⚠️  %v", err)
+			blocked[m.AggregateID] = true
+			continue
+		}
+
+		sent++
+	}
+
+	if sent > 0 {
+		log.Printf("📤 Outbox relay published %d message(s)", sent)
+	}
+
+	return sent, nil
+}
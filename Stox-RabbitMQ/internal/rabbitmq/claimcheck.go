@@ -0,0 +1,124 @@
+package rabbitmq
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"time"
+)
+
+// Claim check headers
+const (
+	HeaderClaimCheck   = "x-claim-check"               // blob key holding the real body
+	HeaderClaimOneShot = "x-claim-check-delete-on-ack" // body is read by exactly one queue
+)
+
+const claimPrefix = "claims/"
+
+// claimCollectInterval is how often clients look for expired claim checks
+const claimCollectInterval = 10 * time.Minute
+
+// checkIn moves an oversized body to the claim check store.
+// It returns the body to send (empty when checked in) and the headers to add.
+func (c *Client) checkIn(exchange string, body []byte) ([]byte, map[string]interface{}, error) {
+	store := c.config.ClaimCheckStore
+	if store == nil || c.config.ClaimCheckThreshold <= 0 || len(body) <= c.config.ClaimCheckThreshold {
+		return body, nil, nil
+	}
+
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return nil, nil, fmt.Errorf("failed to generate claim check id: %w", err)
+	}
+	key := claimPrefix + hex.EncodeToString(id)
+
+	if err := store.Put(context.Background(), key, body); err != nil {
+		return nil, nil, fmt.Errorf("failed to store claim check: %w", err)
+	}
+
+	headers := map[string]interface{}{HeaderClaimCheck: key}
+	// Messages on the default exchange reach a single queue, so the blob can go
+	// once that delivery is acked. Exchange fan-out relies on TTL collection.
+	if exchange == "" {
+		headers[HeaderClaimOneShot] = true
+	}
+
+	log.Printf("🎫 Checked in %d byte payload as %s", len(body), key)
+	return nil, headers, nil
+}
+
+// checkOut resolves a claim check reference back into the message body
+func (c *Client) checkOut(headers map[string]interface{}, body []byte) ([]byte, string, error) {
+	key, _ := headers[HeaderClaimCheck].(string)
+	if key == "" {
+		return body, "", nil
+	}
+	if c.config.ClaimCheckStore == nil {
+		return nil, "", fmt.Errorf("message references claim check %s but no store is configured", key)
+	}
+
+	data, err := c.config.ClaimCheckStore.Get(context.Background(), key)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to resolve claim check %s: %w", key, err)
+	}
+	return data, key, nil
+}
+
+// releaseClaim deletes a one-shot claim check after its message was acked
+func (c *Client) releaseClaim(headers map[string]interface{}, key string) {
+	if key == "" {
+		return
+	}
+	if oneShot, _ := headers[HeaderClaimOneShot].(bool); !oneShot {
+		return
+	}
+	if err := c.config.ClaimCheckStore.Delete(context.Background(), key); err != nil {
+		log.Printf("⚠️  Failed to release claim check %s: %v", key, err)
+	}
+}
+
+// CollectClaimChecks deletes claim check blobs older than ttl every interval
+// until ctx is done. Clients run it themselves when Config.ClaimCheckTTL is
+// set; several collectors on one store are harmless.
+func (c *Client) CollectClaimChecks(ctx context.Context, ttl, interval time.Duration) {
+	store := c.config.ClaimCheckStore
+	if store == nil {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		objects, err := store.List(ctx, claimPrefix)
+		if err != nil {
+			log.Printf("⚠️  Claim check collection failed: %v", err)
+			continue
+		}
+
+		removed := 0
+		cutoff := time.Now().Add(-ttl)
+		for _, obj := range objects {
+			if obj.LastModified.After(cutoff) {
+				continue
+			}
+			if err := store.Delete(ctx, obj.Key); err != nil {
+				log.Printf("⚠️  Failed to delete expired claim check %s: %v", obj.Key, err)
+				continue
+			}
+			removed++
+		}
+
+		if removed > 0 {
+			log.Printf("🧹 Collected %d expired claim check(s)", removed)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"stox-rabbitmq/internal/storage"
+)
+
+func newClaimCheckClient(t *testing.T) (*Client, string) {
+	t.Helper()
+	dir := t.TempDir()
+	store, err := storage.NewFileStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &Client{config: Config{ClaimCheckStore: store, ClaimCheckThreshold: 8}}, dir
+}
+
+func TestClaimCheckRoundTrip(t *testing.T) {
+	c, _ := newClaimCheckClient(t)
+	ctx := context.Background()
+
+	small := []byte("tiny")
+	body, headers, err := c.checkIn("stox.images", small)
+	if err != nil || !bytes.Equal(body, small) || headers != nil {
+		t.Fatalf("small body was checked in: %q %v %v", body, headers, err)
+	}
+
+	large := []byte(`{"images":"a large payload"}`)
+	for _, tt := range []struct {
+		exchange string
+		oneShot  bool
+	}{
+		{"", true},             // one queue reads it
+		{"stox.images", false}, // an exchange may fan it out
+	} {
+		body, headers, err := c.checkIn(tt.exchange, large)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(body) != 0 {
+			t.Errorf("checked in body still sent: %q", body)
+		}
+		if oneShot, _ := headers[HeaderClaimOneShot].(bool); oneShot != tt.oneShot {
+			t.Errorf("exchange %q: one-shot %v, want %v", tt.exchange, oneShot, tt.oneShot)
+		}
+
+		got, key, err := c.checkOut(headers, body)
+		if err != nil || !bytes.Equal(got, large) {
+			t.Fatalf("checked out %q (%v), want %q", got, err, large)
+		}
+
+		c.releaseClaim(headers, key)
+		_, err = c.config.ClaimCheckStore.Get(ctx, key)
+		if released := errors.Is(err, storage.ErrNotFound); released != tt.oneShot {
+			t.Errorf("exchange %q: released on ack %v, want %v", tt.exchange, released, tt.oneShot)
+		}
+	}
+
+	if _, _, err := (&Client{}).checkOut(map[string]interface{}{HeaderClaimCheck: "claims/x"}, nil); err == nil {
+		t.Error("claim check resolved without a store")
+	}
+}
+
+func TestCollectClaimChecks(t *testing.T) {
+	c, dir := newClaimCheckClient(t)
+	store := c.config.ClaimCheckStore
+	ctx := context.Background()
+
+	for _, key := range []string{"claims/old", "claims/new", "products/old"} {
+		if err := store.Put(ctx, key, []byte("payload")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	past := time.Now().Add(-2 * time.Hour)
+	for _, key := range []string{"claims/old", "products/old"} {
+		if err := os.Chtimes(filepath.Join(dir, key), past, past); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		c.CollectClaimChecks(ctx, time.Hour, 5*time.Millisecond)
+		close(done)
+	}()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if _, err := store.Get(context.Background(), "claims/old"); errors.Is(err, storage.ErrNotFound) {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("expired claim check was not collected")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+	cancel()
+	<-done
+
+	for _, key := range []string{"claims/new", "products/old"} {
+		if _, err := store.Get(context.Background(), key); err != nil {
+			t.Errorf("%s was collected: %v", key, err)
+		}
+	}
+}
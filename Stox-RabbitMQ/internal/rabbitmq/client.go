@@ -1,29 +1,86 @@
 package rabbitmq
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/rabbitmq/amqp091-go"
+
+	"stox-rabbitmq/internal/schema"
+	"stox-rabbitmq/internal/storage"
 )
 
 // Client wraps RabbitMQ connection and provides high-level operations
 type Client struct {
-	conn    *amqp091.Connection
-	channel *amqp091.Channel
-	config  Config
+	conn     *amqp091.Connection
+	channel  *amqp091.Channel
+	config   Config
+	confirms bool
+	expected map[string]func([]byte) string // queue -> schema of messages without headers
+	codecs   map[string]string              // exchange -> content type used when publishing
+	metrics  compressionMetrics
+	stop     context.CancelFunc // stops the claim check collector
 }
 
 type Config struct {
 	URL      string
 	Exchange string
 	Queue    string
+
+	// ValidateSchemas rejects published messages that violate their JSON Schema
+	// and moves invalid consumed messages to the dead letter queue
+	ValidateSchemas bool
+
+	// Compression is the content-encoding ("gzip", "zstd", or any registered
+	// Compressor) applied to published bodies of at least CompressionThreshold bytes
+	Compression          string
+	CompressionThreshold int
+
+	// MaxDecompressedSize caps consumed bodies after decompression
+	// (DefaultMaxDecompressedSize if 0); larger messages are dead-lettered
+	MaxDecompressedSize int64
+
+	// ClaimCheckStore receives published bodies larger than ClaimCheckThreshold
+	// bytes; the message then only carries a reference header
+	ClaimCheckStore     storage.Store
+	ClaimCheckThreshold int
+
+	// ClaimCheckDir opens a local FileStore as ClaimCheckStore when no store is given
+	ClaimCheckDir string
+
+	// ClaimCheckTTL is how long claim checks are kept; the client deletes
+	// older ones until it is closed (0 keeps them)
+	ClaimCheckTTL time.Duration
+
+	// Prefetch limits unacknowledged deliveries per consumer (0 for unlimited),
+	// bounding what a paused Consumer still has to handle
+	Prefetch int
 }
 
+// Dead letter exchange and queue for messages that cannot be processed
+const (
+	DeadLetterExchange = "stox.dlx"
+	DeadLetterQueue    = "dead_letters"
+)
+
+// SingleActiveConsumer makes the broker deliver a queue to one consumer at a
+// time, keeping its messages in order while other replicas stand by
+var SingleActiveConsumer = amqp091.Table{"x-single-active-consumer": true}
+
 // NewClient creates a new RabbitMQ client
 func NewClient(config Config) (*Client, error) {
+	if config.ClaimCheckStore == nil && config.ClaimCheckDir != "" {
+		store, err := storage.NewFileStore(config.ClaimCheckDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open claim check store: %w", err)
+		}
+		config.ClaimCheckStore = store
+	}
+
 	conn, err := amqp091.Dial(config.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to RabbitMQ: %w", err)
@@ -35,10 +92,27 @@ func NewClient(config Config) (*Client, error) {
 		return nil, fmt.Errorf("failed to open channel: %w", err)
 	}
 
+	if config.Prefetch > 0 {
+		if err := ch.Qos(config.Prefetch, 0, false); err != nil {
+			conn.Close()
+			return nil, fmt.Errorf("failed to set prefetch: %w", err)
+		}
+	}
+
+	ctx, stop := context.WithCancel(context.Background())
 	client := &Client{
-		conn:    conn,
-		channel: ch,
-		config:  config,
+		conn:     conn,
+		channel:  ch,
+		config:   config,
+		expected: make(map[string]func([]byte) string),
+		codecs:   make(map[string]string),
+		stop:     stop,
+	}
+
+	// Every client sharing a store collects its expired claim checks, so they
+	// go whichever services are deployed
+	if config.ClaimCheckStore != nil && config.ClaimCheckTTL > 0 {
+		go client.CollectClaimChecks(ctx, config.ClaimCheckTTL, claimCollectInterval)
 	}
 
 	return client, nil
@@ -54,36 +128,50 @@ func (c *Client) SetupExchanges() error {
 		{"stox.listings", "fanout"},
 		{"stox.sync", "direct"},
 		{"stox.orders", "topic"},
+		{DeadLetterExchange, "topic"},
+		{CoordinationExchange, "topic"},
 	}
 
 	for _, exchange := range exchanges {
 		err := c.channel.ExchangeDeclare(
 			exchange.name, // name
 			exchange.kind, // type
-			true,         // durable
-			false,        // auto-deleted
-			false,        // internal
-			false,        // no-wait
-			nil,          // arguments
+			true,          // durable
+			false,         // auto-deleted
+			false,         // internal
+			false,         // no-wait
+			nil,           // arguments
 		)
 		if err != nil {
 			return fmt.Errorf("failed to declare exchange %s: %w", exchange.name, err)
 		}
 	}
 
+	// Dead letters are routed by original queue name
+	err := c.DeclareQueue(DeadLetterQueue, DeadLetterExchange, "#")
+	if err != nil {
+		return err
+	}
+
 	log.Println("✅ All exchanges declared successfully")
 	return nil
 }
 
 // DeclareQueue declares a queue and binds it to an exchange
 func (c *Client) DeclareQueue(queueName, exchangeName, routingKey string) error {
+	return c.DeclareQueueWithArgs(queueName, exchangeName, routingKey, nil)
+}
+
+// DeclareQueueWithArgs declares a queue with optional arguments, e.g.
+// SingleActiveConsumer, and binds it to an exchange
+func (c *Client) DeclareQueueWithArgs(queueName, exchangeName, routingKey string, args amqp091.Table) error {
 	_, err := c.channel.QueueDeclare(
 		queueName, // name
 		true,      // durable
 		false,     // delete when unused
 		false,     // exclusive
 		false,     // no-wait
-		nil,       // arguments
+		args,      // arguments
 	)
 	if err != nil {
 		return fmt.Errorf("failed to declare queue %s: %w", queueName, err)
@@ -105,11 +193,72 @@ func (c *Client) DeclareQueue(queueName, exchangeName, routingKey string) error
 	return nil
 }
 
-// PublishMessage publishes a message to an exchange
+// SetExchangeCodec selects the wire format for every message published to an exchange
+func (c *Client) SetExchangeCodec(exchange, contentType string) error {
+	if _, err := CodecFor(contentType); err != nil {
+		return err
+	}
+	c.codecs[exchange] = contentType
+	return nil
+}
+
+// PublishMessage publishes a message to an exchange using the exchange's codec (JSON by default)
 func (c *Client) PublishMessage(exchange, routingKey string, message interface{}) error {
-	body, err := json.Marshal(message)
+	return c.PublishMessageAs(exchange, routingKey, message, c.codecs[exchange])
+}
+
+// PublishMessageAs publishes a message encoded with the codec for contentType
+func (c *Client) PublishMessageAs(exchange, routingKey string, message interface{}, contentType string) error {
+	return c.publish(exchange, routingKey, message, contentType, nil)
+}
+
+// PublishMessageWithHeaders publishes a message like PublishMessage, adding headers to it
+func (c *Client) PublishMessageWithHeaders(exchange, routingKey string, message interface{}, headers map[string]interface{}) error {
+	return c.publish(exchange, routingKey, message, c.codecs[exchange], headers)
+}
+
+func (c *Client) publish(exchange, routingKey string, message interface{}, contentType string, extra map[string]interface{}) error {
+	codec, err := CodecFor(contentType)
+	if err != nil {
+		return err
+	}
+
+	if c.config.ValidateSchemas {
+		violations, err := schema.ValidateMessage(message)
+		if err != nil {
+			return fmt.Errorf("failed to validate message: %w", err)
+		}
+		if len(violations) > 0 {
+			return fmt.Errorf("message violates schema: %s", strings.Join(violations, "; "))
+		}
+	}
+
+	body, err := encodeMessage(codec, message)
 	if err != nil {
-		return fmt.Errorf("failed to marshal message: %w", err)
+		return err
+	}
+
+	raw := len(body)
+	body, encoding, err := c.compress(body)
+	if err != nil {
+		return err
+	}
+	c.metrics.record("exchange:"+exchange, len(body), raw, encoding != "")
+
+	body, claimHeaders, err := c.checkIn(exchange, body)
+	if err != nil {
+		return err
+	}
+
+	headers := amqp091.Table{}
+	for k, v := range extra {
+		headers[k] = v
+	}
+	for k, v := range schema.Headers(message) { // contract name + version
+		headers[k] = v
+	}
+	for k, v := range claimHeaders {
+		headers[k] = v
 	}
 
 	err = c.channel.Publish(
@@ -118,7 +267,48 @@ func (c *Client) PublishMessage(exchange, routingKey string, message interface{}
 		false,      // mandatory
 		false,      // immediate
 		amqp091.Publishing{
-			ContentType:  "application/json",
+			ContentType:     codec.ContentType(),
+			ContentEncoding: encoding,
+			Headers:         headers,
+			Body:            body,
+			DeliveryMode:    amqp091.Persistent, // persistent
+			Timestamp:       time.Now(),
+		},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to publish message: %w", err)
+	}
+
+	return nil
+}
+
+// EnableConfirms puts the channel into publisher confirm mode
+func (c *Client) EnableConfirms() error {
+	if c.confirms {
+		return nil
+	}
+	if err := c.channel.Confirm(false); err != nil {
+		return fmt.Errorf("failed to enable publisher confirms: %w", err)
+	}
+	c.confirms = true
+	return nil
+}
+
+// PublishConfirmed publishes a pre-encoded JSON body and waits for the broker
+// to confirm it. Confirm mode is enabled on first use.
+func (c *Client) PublishConfirmed(ctx context.Context, exchange, routingKey string, body []byte) error {
+	if err := c.EnableConfirms(); err != nil {
+		return err
+	}
+
+	confirm, err := c.channel.PublishWithDeferredConfirmWithContext(
+		ctx,
+		exchange,   // exchange
+		routingKey, // routing key
+		false,      // mandatory
+		false,      // immediate
+		amqp091.Publishing{
+			ContentType:  ContentTypeJSON,
 			Body:         body,
 			DeliveryMode: amqp091.Persistent, // persistent
 			Timestamp:    time.Now(),
@@ -128,11 +318,49 @@ func (c *Client) PublishMessage(exchange, routingKey string, message interface{}
 		return fmt.Errorf("failed to publish message: %w", err)
 	}
 
+	acked, err := confirm.WaitContext(ctx)
+	if err != nil {
+		return fmt.Errorf("failed waiting for publish confirm: %w", err)
+	}
+	if !acked {
+		return fmt.Errorf("message to %s/%s was nacked by broker", exchange, routingKey)
+	}
+
 	return nil
 }
 
+// ExpectSchema sets the contract used to validate messages on a queue that
+// arrive without schema headers, e.g. from non-Go producers
+func (c *Client) ExpectSchema(queueName, schemaName string) {
+	c.ExpectSchemaBy(queueName, func([]byte) string { return schemaName })
+}
+
+// ExpectSchemaBy is ExpectSchema for queues that carry several message types;
+// pick names the contract of a body that arrived without schema headers
+func (c *Client) ExpectSchemaBy(queueName string, pick func(body []byte) string) {
+	c.expected[queueName] = pick
+}
+
+// Delivery is a received message together with its routing metadata.
+// Body is always JSON; ContentType is the format the message travelled in.
+type Delivery struct {
+	Exchange    string
+	RoutingKey  string
+	ContentType string
+	Headers     map[string]interface{}
+	Timestamp   time.Time
+	Body        []byte
+}
+
 // ConsumeMessages consumes messages from a queue
 func (c *Client) ConsumeMessages(queueName string, handler func([]byte) error) error {
+	return c.ConsumeDeliveries(queueName, func(d Delivery) error {
+		return handler(d.Body)
+	})
+}
+
+// ConsumeDeliveries consumes messages from a queue, passing routing metadata to the handler
+func (c *Client) ConsumeDeliveries(queueName string, handler func(Delivery) error) error {
 	msgs, err := c.channel.Consume(
 		queueName, // queue
 		"",        // consumer
@@ -150,16 +378,7 @@ func (c *Client) ConsumeMessages(queueName string, handler func([]byte) error) e
 
 	go func() {
 		for d := range msgs {
-			log.Printf("📨 Received message from queue %s", queueName)
-			
-			err := handler(d.Body)
-			if err != nil {
-				log.Printf("❌ Error processing message: %v", err)
-				d.Nack(false, false) // Negative acknowledgment, don't requeue
-			} else {
-				log.Printf("✅ Message processed successfully")
-				d.Ack(false) // Acknowledge message
-			}
+			c.handleDelivery(queueName, d, handler)
 		}
 	}()
 
@@ -169,8 +388,152 @@ func (c *Client) ConsumeMessages(queueName string, handler func([]byte) error) e
 	return nil
 }
 
+// handleDelivery decodes, validates and hands one delivery to the handler,
+// then acknowledges it
+func (c *Client) handleDelivery(queueName string, d amqp091.Delivery, handler func(Delivery) error) {
+	delivery, claimKey, ok := c.prepareDelivery(queueName, d)
+	if !ok {
+		return
+	}
+	c.settleDelivery(queueName, d, claimKey, handler(delivery))
+}
+
+// prepareDelivery decodes and validates a delivery; messages that cannot be
+// handled are rejected and reported as not ok
+func (c *Client) prepareDelivery(queueName string, d amqp091.Delivery) (Delivery, string, bool) {
+	log.Printf("📨 Received message from queue %s", queueName)
+
+	body, claimKey, err := c.decodeDelivery(queueName, d)
+	if errors.Is(err, ErrBodyTooLarge) {
+		log.Printf("🚫 Message on %s is too large: %v", queueName, err)
+		c.deadLetter(queueName, d, []string{err.Error()})
+		return Delivery{}, "", false
+	}
+	if err != nil {
+		log.Printf("❌ Error decoding message: %v", err)
+		d.Nack(false, false)
+		return Delivery{}, "", false
+	}
+
+	if c.config.ValidateSchemas {
+		if violations := c.validateDelivery(queueName, d.Headers, body); len(violations) > 0 {
+			log.Printf("🚫 Message on %s violates schema: %s", queueName, strings.Join(violations, "; "))
+			c.deadLetter(queueName, d, violations)
+			return Delivery{}, "", false
+		}
+	}
+
+	return Delivery{
+		Exchange:    d.Exchange,
+		RoutingKey:  d.RoutingKey,
+		ContentType: d.ContentType,
+		Headers:     d.Headers,
+		Timestamp:   d.Timestamp,
+		Body:        body,
+	}, claimKey, true
+}
+
+// settleDelivery acknowledges a handled delivery. Failed messages are not
+// requeued unless the handler returned ErrRetryLater.
+func (c *Client) settleDelivery(queueName string, d amqp091.Delivery, claimKey string, err error) {
+	switch {
+	case errors.Is(err, ErrRetryLater):
+		log.Printf("↩️  Requeued message from %s: %v", queueName, err)
+		d.Nack(false, true)
+	case err != nil:
+		log.Printf("❌ Error processing message: %v", err)
+		d.Nack(false, false) // Negative acknowledgment, don't requeue
+	default:
+		log.Printf("✅ Message processed successfully")
+		d.Ack(false) // Acknowledge message
+		c.releaseClaim(d.Headers, claimKey)
+	}
+}
+
+// decodeDelivery turns a delivery into the JSON body handlers expect: it resolves
+// claim checks, decompresses, decodes binary formats and upcasts old contract
+// versions. It also returns the claim check key, if any.
+func (c *Client) decodeDelivery(queueName string, d amqp091.Delivery) ([]byte, string, error) {
+	body, claimKey, err := c.checkOut(d.Headers, d.Body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wire := len(body)
+	body, err = c.decompress(d.ContentEncoding, body)
+	if err != nil {
+		return nil, "", err
+	}
+	c.metrics.record("queue:"+queueName, wire, len(body), d.ContentEncoding != "")
+
+	body, err = toJSON(d.ContentType, body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	body, err = schema.Upcast(d.Headers, body)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, claimKey, nil
+}
+
+// validateDelivery checks a consumed body against the schema named in its
+// headers, falling back to the schema expected on the queue
+func (c *Client) validateDelivery(queueName string, headers amqp091.Table, body []byte) []string {
+	name, _ := headers[schema.HeaderName].(string)
+	if pick := c.expected[queueName]; name == "" && pick != nil {
+		name = pick(body)
+	}
+	if name == "" {
+		return nil
+	}
+
+	violations, err := schema.Validate(name, body)
+	if err != nil {
+		return []string{err.Error()}
+	}
+	return violations
+}
+
+// deadLetter moves a delivery to the dead letter queue with the rejection reasons
+func (c *Client) deadLetter(queueName string, d amqp091.Delivery, reasons []string) {
+	headers := amqp091.Table{}
+	for k, v := range d.Headers {
+		headers[k] = v
+	}
+	headers["x-original-queue"] = queueName
+	headers["x-rejection-reason"] = strings.Join(reasons, "; ")
+
+	err := c.channel.Publish(
+		DeadLetterExchange, // exchange
+		queueName,          // routing key
+		false,              // mandatory
+		false,              // immediate
+		amqp091.Publishing{
+			ContentType:     d.ContentType,
+			ContentEncoding: d.ContentEncoding,
+			Headers:         headers,
+			Body:            d.Body,
+			DeliveryMode:    amqp091.Persistent,
+			Timestamp:       time.Now(),
+		},
+	)
+	if err != nil {
+		log.Printf("❌ Failed to dead-letter message from %s: %v", queueName, err)
+		d.Nack(false, true) // keep it rather than lose it
+		return
+	}
+
+	d.Ack(false)
+}
+
 // Close closes the RabbitMQ connection
 func (c *Client) Close() error {
+	if c.stop != nil {
+		c.stop()
+	}
 	if c.channel != nil {
 		c.channel.Close()
 	}
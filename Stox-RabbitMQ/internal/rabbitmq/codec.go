@@ -0,0 +1,124 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"sync"
+)
+
+// Content types of the built-in codecs
+const (
+	ContentTypeJSON        = "application/json"
+	ContentTypeMessagePack = "application/msgpack"
+	ContentTypeProtobuf    = "application/x-protobuf"
+)
+
+// maxCodecDepth bounds how deeply the binary codecs nest arrays and maps, so
+// a crafted body cannot exhaust the stack
+const maxCodecDepth = 100
+
+var errCodecTooDeep = errors.New("codec: value nested too deeply")
+
+// Codec converts between wire bytes and generic JSON-compatible values
+// (map[string]interface{}, []interface{}, string, json.Number, bool, nil).
+//
+// Going through the generic form lets consumers hand every message to the
+// existing JSON handlers, whatever format it travelled in.
+type Codec interface {
+	ContentType() string
+	Encode(value interface{}) ([]byte, error)
+	Decode(data []byte) (interface{}, error)
+}
+
+var (
+	codecMu sync.RWMutex
+	codecs  = map[string]Codec{
+		ContentTypeJSON:        jsonCodec{},
+		ContentTypeMessagePack: msgpackCodec{},
+		ContentTypeProtobuf:    protobufCodec{},
+	}
+)
+
+// RegisterCodec makes a codec available for publishing and consuming
+func RegisterCodec(codec Codec) {
+	codecMu.Lock()
+	defer codecMu.Unlock()
+	codecs[codec.ContentType()] = codec
+}
+
+// CodecFor returns the codec for a content type; an empty type means JSON
+func CodecFor(contentType string) (Codec, error) {
+	if contentType == "" {
+		contentType = ContentTypeJSON
+	}
+	if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+		contentType = mediaType
+	}
+
+	codecMu.RLock()
+	defer codecMu.RUnlock()
+
+	codec, ok := codecs[contentType]
+	if !ok {
+		return nil, fmt.Errorf("no codec registered for content type %q", contentType)
+	}
+	return codec, nil
+}
+
+// encodeMessage marshals a message with the given codec
+func encodeMessage(codec Codec, message interface{}) ([]byte, error) {
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal message: %w", err)
+	}
+	if codec.ContentType() == ContentTypeJSON {
+		return body, nil
+	}
+
+	value, err := decodeJSON(body)
+	if err != nil {
+		return nil, err
+	}
+	return codec.Encode(value)
+}
+
+// toJSON turns a consumed body of any registered content type into JSON
+func toJSON(contentType string, body []byte) ([]byte, error) {
+	codec, err := CodecFor(contentType)
+	if err != nil {
+		return nil, err
+	}
+	if codec.ContentType() == ContentTypeJSON {
+		return body, nil
+	}
+
+	value, err := codec.Decode(body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s body: %w", codec.ContentType(), err)
+	}
+	return json.Marshal(value)
+}
+
+// decodeJSON decodes JSON keeping numbers exact
+func decodeJSON(data []byte) (interface{}, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	return value, nil
+}
+
+// jsonCodec is the default wire format
+type jsonCodec struct{}
+
+func (jsonCodec) ContentType() string { return ContentTypeJSON }
+
+func (jsonCodec) Encode(value interface{}) ([]byte, error) { return json.Marshal(value) }
+
+func (jsonCodec) Decode(data []byte) (interface{}, error) { return decodeJSON(data) }
@@ -0,0 +1,268 @@
+package rabbitmq
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// msgpackCodec implements the MessagePack format (https://msgpack.org) for
+// generic values. Map keys are written in sorted order so equal messages
+// produce equal bytes.
+type msgpackCodec struct{}
+
+func (msgpackCodec) ContentType() string { return ContentTypeMessagePack }
+
+func (msgpackCodec) Encode(value interface{}) ([]byte, error) {
+	return appendMsgpack(nil, value)
+}
+
+func (msgpackCodec) Decode(data []byte) (interface{}, error) {
+	value, rest, err := readMsgpack(data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(rest) != 0 {
+		return nil, fmt.Errorf("msgpack: %d trailing bytes", len(rest))
+	}
+	return value, nil
+}
+
+func appendMsgpack(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if v {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case json.Number:
+		if i, err := v.Int64(); err == nil {
+			return appendMsgpackInt(buf, i), nil
+		}
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("msgpack: invalid number %q", v)
+		}
+		return appendMsgpackFloat(buf, f), nil
+	case float64:
+		return appendMsgpackFloat(buf, v), nil
+	case string:
+		return appendMsgpackString(buf, v), nil
+	case []interface{}:
+		buf = appendMsgpackHeader(buf, len(v), 0x90, 0xdc, 0xdd)
+		for _, item := range v {
+			var err error
+			if buf, err = appendMsgpack(buf, item); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		buf = appendMsgpackHeader(buf, len(v), 0x80, 0xde, 0xdf)
+		for _, k := range keys {
+			buf = appendMsgpackString(buf, k)
+			var err error
+			if buf, err = appendMsgpack(buf, v[k]); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	}
+	return nil, fmt.Errorf("msgpack: unsupported type %T", value)
+}
+
+func appendMsgpackInt(buf []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= 0x7f:
+		return append(buf, byte(i))
+	case i < 0 && i >= -32:
+		return append(buf, byte(int8(i)))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return append(buf, 0xd0, byte(int8(i)))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(int16(i)))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(int32(i)))
+	}
+	return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(i))
+}
+
+func appendMsgpackFloat(buf []byte, f float64) []byte {
+	return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(f))
+}
+
+func appendMsgpackString(buf []byte, s string) []byte {
+	n := len(s)
+	switch {
+	case n < 32:
+		buf = append(buf, 0xa0|byte(n))
+	case n <= math.MaxUint8:
+		buf = append(buf, 0xd9, byte(n))
+	case n <= math.MaxUint16:
+		buf = binary.BigEndian.AppendUint16(append(buf, 0xda), uint16(n))
+	default:
+		buf = binary.BigEndian.AppendUint32(append(buf, 0xdb), uint32(n))
+	}
+	return append(buf, s...)
+}
+
+// appendMsgpackHeader writes an array or map length using the fix/16/32 forms
+func appendMsgpackHeader(buf []byte, n int, fix, b16, b32 byte) []byte {
+	switch {
+	case n < 16:
+		return append(buf, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, b16), uint16(n))
+	}
+	return binary.BigEndian.AppendUint32(append(buf, b32), uint32(n))
+}
+
+var errMsgpackShort = errors.New("msgpack: unexpected end of data")
+
+// readMsgpack decodes one value at a nesting depth and returns the remaining bytes
+func readMsgpack(data []byte, depth int) (interface{}, []byte, error) {
+	if len(data) == 0 {
+		return nil, nil, errMsgpackShort
+	}
+	if depth > maxCodecDepth {
+		return nil, nil, errCodecTooDeep
+	}
+
+	b, data := data[0], data[1:]
+	switch {
+	case b <= 0x7f:
+		return int64(b), data, nil
+	case b >= 0xe0:
+		return int64(int8(b)), data, nil
+	case b&0xe0 == 0xa0:
+		return readMsgpackString(data, int(b&0x1f))
+	case b&0xf0 == 0x90:
+		return readMsgpackArray(data, int(b&0x0f), depth)
+	case b&0xf0 == 0x80:
+		return readMsgpackMap(data, int(b&0x0f), depth)
+	}
+
+	switch b {
+	case 0xc0:
+		return nil, data, nil
+	case 0xc2:
+		return false, data, nil
+	case 0xc3:
+		return true, data, nil
+	case 0xca:
+		u, rest, err := readUint(data, 4)
+		return float64(math.Float32frombits(uint32(u))), rest, err
+	case 0xcb:
+		u, rest, err := readUint(data, 8)
+		return math.Float64frombits(u), rest, err
+	case 0xcc, 0xcd, 0xce, 0xcf:
+		u, rest, err := readUint(data, 1<<(b-0xcc))
+		return u, rest, err
+	case 0xd0:
+		u, rest, err := readUint(data, 1)
+		return int64(int8(u)), rest, err
+	case 0xd1:
+		u, rest, err := readUint(data, 2)
+		return int64(int16(u)), rest, err
+	case 0xd2:
+		u, rest, err := readUint(data, 4)
+		return int64(int32(u)), rest, err
+	case 0xd3:
+		u, rest, err := readUint(data, 8)
+		return int64(u), rest, err
+	case 0xd9, 0xda, 0xdb, 0xc4, 0xc5, 0xc6: // str8/16/32 and bin8/16/32
+		size := map[byte]int{0xd9: 1, 0xda: 2, 0xdb: 4, 0xc4: 1, 0xc5: 2, 0xc6: 4}[b]
+		n, rest, err := readUint(data, size)
+		if err != nil {
+			return nil, nil, err
+		}
+		return readMsgpackString(rest, int(n))
+	case 0xdc, 0xdd:
+		n, rest, err := readUint(data, 2<<(b-0xdc))
+		if err != nil {
+			return nil, nil, err
+		}
+		return readMsgpackArray(rest, int(n), depth)
+	case 0xde, 0xdf:
+		n, rest, err := readUint(data, 2<<(b-0xde))
+		if err != nil {
+			return nil, nil, err
+		}
+		return readMsgpackMap(rest, int(n), depth)
+	}
+
+	return nil, nil, fmt.Errorf("msgpack: unsupported format byte 0x%02x", b)
+}
+
+func readUint(data []byte, size int) (uint64, []byte, error) {
+	if len(data) < size {
+		return 0, nil, errMsgpackShort
+	}
+	var u uint64
+	for _, b := range data[:size] {
+		u = u<<8 | uint64(b)
+	}
+	return u, data[size:], nil
+}
+
+func readMsgpackString(data []byte, n int) (interface{}, []byte, error) {
+	if len(data) < n {
+		return nil, nil, errMsgpackShort
+	}
+	return string(data[:n]), data[n:], nil
+}
+
+// readMsgpackArray reads n items; every item takes at least one byte, so a
+// length beyond the data left is rejected before allocating
+func readMsgpackArray(data []byte, n, depth int) (interface{}, []byte, error) {
+	if n > len(data) {
+		return nil, nil, errMsgpackShort
+	}
+	items := make([]interface{}, 0, n)
+	for i := 0; i < n; i++ {
+		item, rest, err := readMsgpack(data, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		items = append(items, item)
+		data = rest
+	}
+	return items, data, nil
+}
+
+// readMsgpackMap reads n entries of at least two bytes each
+func readMsgpackMap(data []byte, n, depth int) (interface{}, []byte, error) {
+	if n > len(data)/2 {
+		return nil, nil, errMsgpackShort
+	}
+	m := make(map[string]interface{}, n)
+	for i := 0; i < n; i++ {
+		key, rest, err := readMsgpack(data, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		k, ok := key.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("msgpack: map key of type %T", key)
+		}
+
+		value, rest, err := readMsgpack(rest, depth+1)
+		if err != nil {
+			return nil, nil, err
+		}
+		m[k] = value
+		data = rest
+	}
+	return m, data, nil
+}
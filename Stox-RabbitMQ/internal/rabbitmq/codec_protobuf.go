@@ -0,0 +1,241 @@
+package rabbitmq
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+)
+
+// protobufCodec encodes messages as a google.protobuf.Value
+// (google/protobuf/struct.proto). Any protobuf runtime can decode the body
+// with the well-known Struct types, so no .proto files have to be shared.
+// Field names travel with every message; use MessagePack when size matters most.
+type protobufCodec struct{}
+
+func (protobufCodec) ContentType() string { return ContentTypeProtobuf }
+
+func (protobufCodec) Encode(value interface{}) ([]byte, error) {
+	return appendProtoValue(nil, value)
+}
+
+func (protobufCodec) Decode(data []byte) (interface{}, error) {
+	return readProtoValue(data, 0)
+}
+
+// Field numbers and wire types from struct.proto
+const (
+	protoNullValue   = 1
+	protoNumberValue = 2
+	protoStringValue = 3
+	protoBoolValue   = 4
+	protoStructValue = 5
+	protoListValue   = 6
+
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+)
+
+func appendProtoTag(buf []byte, field, wire int) []byte {
+	return binary.AppendUvarint(buf, uint64(field<<3|wire))
+}
+
+func appendProtoBytes(buf []byte, field int, data []byte) []byte {
+	buf = appendProtoTag(buf, field, wireBytes)
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// appendProtoValue writes value as the fields of a google.protobuf.Value
+func appendProtoValue(buf []byte, value interface{}) ([]byte, error) {
+	switch v := value.(type) {
+	case nil:
+		return binary.AppendUvarint(appendProtoTag(buf, protoNullValue, wireVarint), 0), nil
+	case bool:
+		b := uint64(0)
+		if v {
+			b = 1
+		}
+		return binary.AppendUvarint(appendProtoTag(buf, protoBoolValue, wireVarint), b), nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("protobuf: invalid number %q", v)
+		}
+		return appendProtoNumber(buf, f), nil
+	case float64:
+		return appendProtoNumber(buf, v), nil
+	case string:
+		return appendProtoBytes(buf, protoStringValue, []byte(v)), nil
+	case []interface{}:
+		var list []byte // ListValue: repeated Value values = 1
+		for _, item := range v {
+			encoded, err := appendProtoValue(nil, item)
+			if err != nil {
+				return nil, err
+			}
+			list = appendProtoBytes(list, 1, encoded)
+		}
+		return appendProtoBytes(buf, protoListValue, list), nil
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		var fields []byte // Struct: map<string, Value> fields = 1
+		for _, k := range keys {
+			encoded, err := appendProtoValue(nil, v[k])
+			if err != nil {
+				return nil, err
+			}
+			entry := appendProtoBytes(nil, 1, []byte(k))
+			entry = appendProtoBytes(entry, 2, encoded)
+			fields = appendProtoBytes(fields, 1, entry)
+		}
+		return appendProtoBytes(buf, protoStructValue, fields), nil
+	}
+	return nil, fmt.Errorf("protobuf: unsupported type %T", value)
+}
+
+func appendProtoNumber(buf []byte, f float64) []byte {
+	buf = appendProtoTag(buf, protoNumberValue, wireFixed64)
+	return binary.LittleEndian.AppendUint64(buf, math.Float64bits(f))
+}
+
+var errProtoShort = errors.New("protobuf: unexpected end of data")
+
+// protoField is one decoded field of a message
+type protoField struct {
+	number int
+	varint uint64
+	bytes  []byte
+}
+
+// readProtoFields splits a message into its fields
+func readProtoFields(data []byte) ([]protoField, error) {
+	var fields []protoField
+	for len(data) > 0 {
+		tag, n := binary.Uvarint(data)
+		if n <= 0 {
+			return nil, errProtoShort
+		}
+		data = data[n:]
+
+		field := protoField{number: int(tag >> 3)}
+		switch tag & 7 {
+		case wireVarint:
+			v, n := binary.Uvarint(data)
+			if n <= 0 {
+				return nil, errProtoShort
+			}
+			field.varint, data = v, data[n:]
+		case wireFixed64:
+			if len(data) < 8 {
+				return nil, errProtoShort
+			}
+			field.varint, data = binary.LittleEndian.Uint64(data), data[8:]
+		case wireBytes:
+			size, n := binary.Uvarint(data)
+			if n <= 0 || uint64(len(data)-n) < size {
+				return nil, errProtoShort
+			}
+			field.bytes, data = data[n:n+int(size)], data[n+int(size):]
+		default:
+			return nil, fmt.Errorf("protobuf: unsupported wire type %d", tag&7)
+		}
+		fields = append(fields, field)
+	}
+	return fields, nil
+}
+
+// readProtoValue decodes a google.protobuf.Value at a nesting depth
+func readProtoValue(data []byte, depth int) (interface{}, error) {
+	if depth > maxCodecDepth {
+		return nil, errCodecTooDeep
+	}
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	for _, f := range fields { // last kind wins, as in protobuf oneofs
+		switch f.number {
+		case protoNullValue:
+			value = nil
+		case protoNumberValue:
+			value = math.Float64frombits(f.varint)
+		case protoStringValue:
+			value = string(f.bytes)
+		case protoBoolValue:
+			value = f.varint != 0
+		case protoStructValue:
+			if value, err = readProtoStruct(f.bytes, depth); err != nil {
+				return nil, err
+			}
+		case protoListValue:
+			if value, err = readProtoList(f.bytes, depth); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return value, nil
+}
+
+func readProtoStruct(data []byte, depth int) (interface{}, error) {
+	entries, err := readProtoFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{}, len(entries))
+	for _, entry := range entries {
+		if entry.number != 1 {
+			continue
+		}
+		kv, err := readProtoFields(entry.bytes)
+		if err != nil {
+			return nil, err
+		}
+
+		var key string
+		var value interface{}
+		for _, f := range kv {
+			switch f.number {
+			case 1:
+				key = string(f.bytes)
+			case 2:
+				if value, err = readProtoValue(f.bytes, depth+1); err != nil {
+					return nil, err
+				}
+			}
+		}
+		m[key] = value
+	}
+	return m, nil
+}
+
+func readProtoList(data []byte, depth int) (interface{}, error) {
+	fields, err := readProtoFields(data)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]interface{}, 0, len(fields))
+	for _, f := range fields {
+		if f.number != 1 {
+			continue
+		}
+		item, err := readProtoValue(f.bytes, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
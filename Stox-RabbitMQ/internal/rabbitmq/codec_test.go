@@ -0,0 +1,133 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+// codecSamples are JSON messages the binary codecs must carry unchanged
+var codecSamples = []string{
+	`{"product_id":"prod_1","version":9007199254740991,"price":249.9,"tags":["a","b"],"active":true,"note":null}`,
+	`{"nested":{"list":[1,2,{"deep":[[],{}]}]},"empty":""}`,
+	`[1,-2,3.5,"x",false]`,
+	`"plain"`,
+}
+
+func TestCodecsRoundTrip(t *testing.T) {
+	for _, contentType := range []string{ContentTypeMessagePack, ContentTypeProtobuf} {
+		codec, err := CodecFor(contentType)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, sample := range codecSamples {
+			body, err := encodeMessage(codec, json.RawMessage(sample))
+			if err != nil {
+				t.Fatalf("%s: encode %s: %v", contentType, sample, err)
+			}
+			got, err := toJSON(contentType, body)
+			if err != nil {
+				t.Fatalf("%s: decode %s: %v", contentType, sample, err)
+			}
+			if !jsonEqual(t, got, []byte(sample)) {
+				t.Errorf("%s: round trip gave %s, want %s", contentType, got, sample)
+			}
+		}
+	}
+}
+
+func TestMsgpackRejectsOversizedLengths(t *testing.T) {
+	for _, body := range [][]byte{
+		{0xdd, 0x7f, 0xff, 0xff, 0xff},
+		{0xdf, 0x7f, 0xff, 0xff, 0xff},
+		{0xdc, 0xff, 0xff, 0x01},
+		{0xde, 0x00, 0x02, 0xa1, 'k'},
+		{0xdb, 0x7f, 0xff, 0xff, 0xff},
+	} {
+		if _, err := (msgpackCodec{}).Decode(body); err == nil {
+			t.Errorf("decoded % x, want an error", body)
+		}
+	}
+}
+
+func TestCodecsRejectDeepNesting(t *testing.T) {
+	deep := bytes.Repeat([]byte{0x91}, maxCodecDepth+10)
+	deep = append(deep, 0xc0)
+	if _, err := (msgpackCodec{}).Decode(deep); err == nil {
+		t.Error("msgpack decoded a value nested past the limit")
+	}
+
+	nested := json.RawMessage(`null`)
+	for i := 0; i < maxCodecDepth+10; i++ {
+		nested = json.RawMessage(`[` + string(nested) + `]`)
+	}
+	body, err := encodeMessage(protobufCodec{}, nested)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := (protobufCodec{}).Decode(body); err == nil {
+		t.Error("protobuf decoded a value nested past the limit")
+	}
+}
+
+func FuzzMsgpackDecode(f *testing.F) {
+	fuzzCodec(f, msgpackCodec{})
+}
+
+func FuzzProtobufDecode(f *testing.F) {
+	fuzzCodec(f, protobufCodec{})
+}
+
+// fuzzCodec checks that arbitrary bytes never crash a codec, and that
+// whatever it decodes survives another encode and decode
+func fuzzCodec(f *testing.F, codec Codec) {
+	for _, sample := range codecSamples {
+		body, err := encodeMessage(codec, json.RawMessage(sample))
+		if err != nil {
+			f.Fatal(err)
+		}
+		f.Add(body)
+	}
+	f.Add([]byte{0xdd, 0x7f, 0xff, 0xff, 0xff})
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		value, err := codec.Decode(data)
+		if err != nil {
+			return
+		}
+		first, err := json.Marshal(value)
+		if err != nil {
+			return
+		}
+		body, err := encodeMessage(codec, json.RawMessage(first))
+		if err != nil {
+			t.Fatalf("re-encode %s: %v", first, err)
+		}
+		again, err := codec.Decode(body)
+		if err != nil {
+			t.Fatalf("decode re-encoded %s: %v", first, err)
+		}
+		second, err := json.Marshal(again)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !jsonEqual(t, first, second) {
+			t.Fatalf("re-encoding changed %s to %s", first, second)
+		}
+	})
+}
+
+// jsonEqual compares two JSON documents by value
+func jsonEqual(t *testing.T, a, b []byte) bool {
+	t.Helper()
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatalf("invalid JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatalf("invalid JSON %s: %v", b, err)
+	}
+	xs, _ := json.Marshal(x)
+	ys, _ := json.Marshal(y)
+	return bytes.Equal(xs, ys)
+}
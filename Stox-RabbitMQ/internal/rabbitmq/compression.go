@@ -0,0 +1,265 @@
+package rabbitmq
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// DefaultCompressionThreshold is used when compression is on but no threshold is set
+const DefaultCompressionThreshold = 4 * 1024
+
+// DefaultMaxDecompressedSize caps decompressed bodies when no limit is configured
+const DefaultMaxDecompressedSize = 64 << 20
+
+// ErrBodyTooLarge is returned for bodies that decompress beyond the limit;
+// such messages are dead-lettered rather than inflated
+var ErrBodyTooLarge = errors.New("decompressed body exceeds the size limit")
+
+// Compressor implements one content-encoding. gzip and zstd are built in.
+// Decompress must fail with ErrBodyTooLarge rather than produce more than
+// limit bytes.
+type Compressor interface {
+	Encoding() string
+	Compress(data []byte) ([]byte, error)
+	Decompress(data []byte, limit int64) ([]byte, error)
+}
+
+var (
+	compressorMu sync.RWMutex
+	compressors  = map[string]Compressor{
+		"gzip": gzipCompressor{},
+		"zstd": zstdCompressor{},
+	}
+)
+
+// RegisterCompressor makes a content-encoding available for publishing and consuming
+func RegisterCompressor(compressor Compressor) {
+	compressorMu.Lock()
+	defer compressorMu.Unlock()
+	compressors[compressor.Encoding()] = compressor
+}
+
+// CompressorFor returns the compressor for a content-encoding
+func CompressorFor(encoding string) (Compressor, error) {
+	compressorMu.RLock()
+	defer compressorMu.RUnlock()
+
+	compressor, ok := compressors[encoding]
+	if !ok {
+		return nil, fmt.Errorf("no compressor registered for content-encoding %q", encoding)
+	}
+	return compressor, nil
+}
+
+// compress applies the configured compression to bodies above the threshold.
+// It returns the body to send and its content-encoding ("" if uncompressed).
+func (c *Client) compress(body []byte) ([]byte, string, error) {
+	if c.config.Compression == "" {
+		return body, "", nil
+	}
+
+	threshold := c.config.CompressionThreshold
+	if threshold <= 0 {
+		threshold = DefaultCompressionThreshold
+	}
+	if len(body) < threshold {
+		return body, "", nil
+	}
+
+	compressor, err := CompressorFor(c.config.Compression)
+	if err != nil {
+		return nil, "", err
+	}
+
+	compressed, err := compressor.Compress(body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to compress message: %w", err)
+	}
+	if len(compressed) >= len(body) {
+		return body, "", nil // not worth it
+	}
+
+	return compressed, compressor.Encoding(), nil
+}
+
+// decompress reverses a content-encoding on a consumed body, refusing bodies
+// that would inflate beyond MaxDecompressedSize
+func (c *Client) decompress(encoding string, body []byte) ([]byte, error) {
+	if encoding == "" || encoding == "identity" {
+		return body, nil
+	}
+
+	compressor, err := CompressorFor(encoding)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := c.config.MaxDecompressedSize
+	if limit <= 0 {
+		limit = DefaultMaxDecompressedSize
+	}
+	data, err := compressor.Decompress(body, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decompress %s body: %w", encoding, err)
+	}
+	return data, nil
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) Encoding() string { return "gzip" }
+
+func (gzipCompressor) Compress(data []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gzipCompressor) Decompress(data []byte, limit int64) ([]byte, error) {
+	r, err := gzip.NewReader(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+	return readLimited(r, limit)
+}
+
+// zstdEncoder is safe for concurrent EncodeAll calls and costly to create
+var zstdEncoder, _ = zstd.NewWriter(nil)
+
+type zstdCompressor struct{}
+
+func (zstdCompressor) Encoding() string { return "zstd" }
+
+func (zstdCompressor) Compress(data []byte) ([]byte, error) {
+	return zstdEncoder.EncodeAll(data, nil), nil
+}
+
+func (zstdCompressor) Decompress(data []byte, limit int64) ([]byte, error) {
+	// Frames declare their size; refuse oversized ones before allocating
+	var header zstd.Header
+	if err := header.Decode(data); err == nil && header.HasFCS && header.FrameContentSize > uint64(limit) {
+		return nil, ErrBodyTooLarge
+	}
+
+	r, err := zstd.NewReader(bytes.NewReader(data), zstd.WithDecoderConcurrency(1), zstd.WithDecoderMaxMemory(uint64(limit)))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	out, err := readLimited(r, limit)
+	if errors.Is(err, zstd.ErrWindowSizeExceeded) || errors.Is(err, zstd.ErrDecoderSizeExceeded) {
+		return nil, ErrBodyTooLarge // the frame needs more memory than the limit
+	}
+	return out, err
+}
+
+// readLimited reads at most limit bytes, failing with ErrBodyTooLarge if there are more
+func readLimited(r io.Reader, limit int64) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, limit+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > limit {
+		return nil, ErrBodyTooLarge
+	}
+	return data, nil
+}
+
+// CompressionStat aggregates message sizes for one queue or exchange
+type CompressionStat struct {
+	Messages   int64
+	Compressed int64 // messages that travelled compressed
+	WireBytes  int64 // bytes as sent over AMQP
+	RawBytes   int64 // bytes after decompression
+}
+
+// Ratio returns raw/wire bytes; 1.0 means no savings
+func (s CompressionStat) Ratio() float64 {
+	if s.WireBytes == 0 {
+		return 1
+	}
+	return float64(s.RawBytes) / float64(s.WireBytes)
+}
+
+// compressionMetrics tracks sizes per queue (consumed) and exchange (published)
+type compressionMetrics struct {
+	mu    sync.Mutex
+	stats map[string]*CompressionStat
+}
+
+func (m *compressionMetrics) record(key string, wire, raw int, compressed bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.stats == nil {
+		m.stats = make(map[string]*CompressionStat)
+	}
+	stat, ok := m.stats[key]
+	if !ok {
+		stat = &CompressionStat{}
+		m.stats[key] = stat
+	}
+
+	stat.Messages++
+	stat.WireBytes += int64(wire)
+	stat.RawBytes += int64(raw)
+	if compressed {
+		stat.Compressed++
+	}
+}
+
+func (m *compressionMetrics) snapshot() map[string]CompressionStat {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	out := make(map[string]CompressionStat, len(m.stats))
+	for k, v := range m.stats {
+		out[k] = *v
+	}
+	return out
+}
+
+// CompressionStats returns size statistics keyed by "queue:<name>" for consumed
+// messages and "exchange:<name>" for published ones
+func (c *Client) CompressionStats() map[string]CompressionStat {
+	return c.metrics.snapshot()
+}
+
+// ReportCompressionStats logs compression statistics at the given interval
+func (c *Client) ReportCompressionStats(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		stats := c.CompressionStats()
+
+		keys := make([]string, 0, len(stats))
+		for k := range stats {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			s := stats[k]
+			log.Printf("🗜️  %s: %d msgs (%d compressed), %d -> %d bytes, ratio %.2fx",
+				k, s.Messages, s.Compressed, s.RawBytes, s.WireBytes, s.Ratio())
+		}
+	}
+}
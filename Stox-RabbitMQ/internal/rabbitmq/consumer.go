@@ -0,0 +1,218 @@
+package rabbitmq
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// ErrRetryLater is wrapped by handler errors that should requeue the message
+// instead of dead-lettering it, e.g. while a downstream API is unavailable
+var ErrRetryLater = errors.New("retry later")
+
+// consumerSeq numbers consumer tags
+var consumerSeq atomic.Int64
+
+// Consumer is a queue consumer that can be paused and resumed. While paused
+// the broker keeps its messages; deliveries already received are still handled.
+type Consumer struct {
+	client  *Client
+	channel *amqp091.Channel
+	queue   string
+	run     func(msgs <-chan amqp091.Delivery)
+
+	mu      sync.Mutex
+	tag     string // "" while paused
+	running sync.WaitGroup
+	own     bool // channel opened for this consumer
+}
+
+// StartConsumer starts consuming a queue in the background
+func (c *Client) StartConsumer(queueName string, handler func(Delivery) error) (*Consumer, error) {
+	consumer := &Consumer{
+		client:  c,
+		channel: c.channel,
+		queue:   queueName,
+		run: func(msgs <-chan amqp091.Delivery) {
+			for d := range msgs {
+				c.handleDelivery(queueName, d, handler)
+			}
+		},
+	}
+	if err := consumer.Resume(); err != nil {
+		return nil, err
+	}
+	log.Printf("🎧 Waiting for messages from queue: %s", queueName)
+	return consumer, nil
+}
+
+// StartBatchConsumer starts consuming a queue in batches: the handler gets up
+// to size deliveries, collected for at most window after the first arrives,
+// and returns one error per delivery. The consumer uses its own channel with a
+// prefetch of size so that a full batch can be outstanding.
+func (c *Client) StartBatchConsumer(queueName string, size int, window time.Duration, handler func([]Delivery) []error) (*Consumer, error) {
+	if size < 1 {
+		size = 1
+	}
+
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open batch channel: %w", err)
+	}
+	if err := ch.Qos(size, 0, false); err != nil {
+		ch.Close()
+		return nil, fmt.Errorf("failed to set batch prefetch: %w", err)
+	}
+
+	consumer := &Consumer{
+		client:  c,
+		channel: ch,
+		queue:   queueName,
+		run: func(msgs <-chan amqp091.Delivery) {
+			c.runBatches(queueName, msgs, size, window, handler)
+		},
+		own: true,
+	}
+	if err := consumer.Resume(); err != nil {
+		ch.Close()
+		return nil, err
+	}
+	log.Printf("🎧 Waiting for messages from queue: %s (batches of up to %d within %s)", queueName, size, window)
+	return consumer, nil
+}
+
+// pendingDelivery is a prepared delivery waiting for its batch
+type pendingDelivery struct {
+	raw      amqp091.Delivery
+	delivery Delivery
+	claimKey string
+}
+
+// runBatches collects deliveries into batches until msgs is closed
+func (c *Client) runBatches(queueName string, msgs <-chan amqp091.Delivery, size int, window time.Duration, handler func([]Delivery) []error) {
+	var batch []pendingDelivery
+	timer := time.NewTimer(window)
+	timer.Stop()
+
+	flush := func() {
+		timer.Stop()
+		if len(batch) == 0 {
+			return
+		}
+
+		deliveries := make([]Delivery, len(batch))
+		for i, p := range batch {
+			deliveries[i] = p.delivery
+		}
+		log.Printf("📦 Handling batch of %d from %s", len(batch), queueName)
+
+		errs := handler(deliveries)
+		for i, p := range batch {
+			var err error
+			if i < len(errs) {
+				err = errs[i]
+			}
+			c.settleDelivery(queueName, p.raw, p.claimKey, err)
+		}
+		batch = nil
+	}
+
+	for {
+		select {
+		case d, ok := <-msgs:
+			if !ok {
+				flush()
+				return
+			}
+			delivery, claimKey, ok := c.prepareDelivery(queueName, d)
+			if !ok {
+				continue
+			}
+			batch = append(batch, pendingDelivery{raw: d, delivery: delivery, claimKey: claimKey})
+			if len(batch) == 1 {
+				timer.Reset(window)
+			}
+			if len(batch) >= size {
+				flush()
+			}
+		case <-timer.C:
+			flush()
+		}
+	}
+}
+
+// Queue is the consumed queue
+func (k *Consumer) Queue() string { return k.queue }
+
+// Paused reports whether the consumer is paused
+func (k *Consumer) Paused() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.tag == ""
+}
+
+// Pause cancels the consumer so the broker stops delivering to it
+func (k *Consumer) Pause() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.tag == "" {
+		return nil
+	}
+	if err := k.channel.Cancel(k.tag, false); err != nil {
+		return fmt.Errorf("failed to pause consumer on %s: %w", k.queue, err)
+	}
+	k.tag = ""
+	return nil
+}
+
+// Resume registers the consumer again after Pause
+func (k *Consumer) Resume() error {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if k.tag != "" {
+		return nil
+	}
+
+	tag := fmt.Sprintf("%s-%d", k.queue, consumerSeq.Add(1))
+	msgs, err := k.channel.Consume(
+		k.queue, // queue
+		tag,     // consumer
+		false,   // auto-ack
+		false,   // exclusive
+		false,   // no-local
+		false,   // no-wait
+		nil,     // args
+	)
+	if err != nil {
+		return fmt.Errorf("failed to register consumer: %w", err)
+	}
+	k.tag = tag
+
+	k.running.Add(1)
+	go func() {
+		defer k.running.Done()
+		k.run(msgs)
+	}()
+	return nil
+}
+
+// Close stops the consumer for good once the deliveries it holds are handled,
+// so the broker can hand the queue to another consumer
+func (k *Consumer) Close() error {
+	if err := k.Pause(); err != nil {
+		return err
+	}
+	k.running.Wait()
+
+	if k.own {
+		return k.channel.Close()
+	}
+	return nil
+}
@@ -0,0 +1,410 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"os"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/rabbitmq/amqp091-go"
+)
+
+// CoordinationExchange carries the heartbeats of partition coordinators,
+// routed by group
+const CoordinationExchange = "stox.coordination"
+
+// Partition maps a key onto one of count partitions with jump consistent
+// hashing: when count grows by one, only 1/count of the keys move
+func Partition(key string, count int) int {
+	if count <= 1 {
+		return 0
+	}
+
+	h := fnv.New64a()
+	h.Write([]byte(key))
+	k := h.Sum64()
+
+	var b, j int64 = -1, 0
+	for j < int64(count) {
+		b = j
+		k = k*2862933555777941757 + 1
+		j = int64(float64(b+1) * (float64(int64(1)<<31) / float64((k>>33)+1)))
+	}
+	return int(b)
+}
+
+// PartitionQueue names partition n of a partitioned queue. Partitions are
+// always suffixed, even when there is only one, so they never collide with
+// the unpartitioned queue they replace (see RetireQueue).
+func PartitionQueue(queueName string, n int) string {
+	return fmt.Sprintf("%s.%d", queueName, n)
+}
+
+// RetireQueue unbinds a queue that has been replaced, e.g. by its partitions,
+// moves the messages left in it to the routing keys route picks on the same
+// exchange, then deletes it. Messages route cannot place are dead-lettered.
+// A queue that does not exist is already retired.
+func (c *Client) RetireQueue(queueName, exchangeName, routingKey string, route func(Delivery) (string, error)) error {
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return fmt.Errorf("failed to open channel: %w", err)
+	}
+	defer ch.Close()
+
+	if _, err := ch.QueueDeclarePassive(queueName, true, false, false, false, nil); err != nil {
+		var amqpErr *amqp091.Error
+		if errors.As(err, &amqpErr) && amqpErr.Code == amqp091.NotFound {
+			return nil
+		}
+		return fmt.Errorf("failed to inspect queue %s: %w", queueName, err)
+	}
+	if err := ch.QueueUnbind(queueName, routingKey, exchangeName, nil); err != nil {
+		return fmt.Errorf("failed to unbind queue %s: %w", queueName, err)
+	}
+
+	moved := 0
+	for {
+		d, ok, err := ch.Get(queueName, false)
+		if err != nil {
+			return fmt.Errorf("failed to drain queue %s: %w", queueName, err)
+		}
+		if !ok {
+			break
+		}
+
+		body, _, err := c.decodeDelivery(queueName, d)
+		var key string
+		if err == nil {
+			key, err = route(Delivery{
+				Exchange:    d.Exchange,
+				RoutingKey:  d.RoutingKey,
+				ContentType: d.ContentType,
+				Headers:     d.Headers,
+				Timestamp:   d.Timestamp,
+				Body:        body,
+			})
+		}
+		if err != nil {
+			c.deadLetter(queueName, d, []string{err.Error()})
+			continue
+		}
+
+		// The message moves as it is, encoded and claim-checked
+		err = ch.Publish(exchangeName, key, false, false, amqp091.Publishing{
+			ContentType:     d.ContentType,
+			ContentEncoding: d.ContentEncoding,
+			Headers:         d.Headers,
+			Body:            d.Body,
+			DeliveryMode:    amqp091.Persistent,
+			Timestamp:       d.Timestamp,
+		})
+		if err != nil {
+			d.Nack(false, true)
+			return fmt.Errorf("failed to move message from %s to %s: %w", queueName, key, err)
+		}
+		d.Ack(false)
+		moved++
+	}
+
+	if _, err := ch.QueueDelete(queueName, false, true, false); err != nil {
+		return fmt.Errorf("failed to delete queue %s: %w", queueName, err)
+	}
+	log.Printf("🗑️  Retired queue %s (%d message(s) moved)", queueName, moved)
+	return nil
+}
+
+// CoordinatorConfig describes a group of partitions shared by replicas
+type CoordinatorConfig struct {
+	Group      string // e.g. amazon_sync; replicas of a group share its partitions
+	Partitions int
+	Member     string        // this replica's ID, hostname-pid by default
+	Heartbeat  time.Duration // 2s by default; members are dropped after 3 missed heartbeats
+
+	// Assign starts consuming a partition that this replica now owns, Revoke
+	// stops it. Revoke returns once the partition's in-flight messages are settled.
+	Assign func(partition int) error
+	Revoke func(partition int)
+}
+
+// heartbeat announces a member of a group
+type heartbeat struct {
+	Group   string `json:"group"`
+	Member  string `json:"member"`
+	Leaving bool   `json:"leaving,omitempty"`
+}
+
+// Coordinator spreads a group's partitions over the replicas that are alive.
+// Replicas announce themselves with heartbeats on CoordinationExchange; each
+// one assigns partitions with rendezvous hashing over the members it has
+// heard from, so all replicas agree and scaling up or down only moves the
+// partitions of the members that came or went. Partition queues should be
+// declared with SingleActiveConsumer: while replicas briefly disagree during
+// a rebalance, the broker still lets only one of them consume a partition.
+type Coordinator struct {
+	client  *Client
+	channel *amqp091.Channel
+	config  CoordinatorConfig
+
+	busy    sync.Mutex // serializes rebalances and Stop
+	mu      sync.Mutex
+	members map[string]time.Time // member -> last heartbeat
+	owned   map[int]bool
+	stopped bool
+}
+
+// NewCoordinator joins a partition group
+func (c *Client) NewCoordinator(config CoordinatorConfig) (*Coordinator, error) {
+	if config.Partitions < 1 {
+		config.Partitions = 1
+	}
+	if config.Heartbeat == 0 {
+		config.Heartbeat = 2 * time.Second
+	}
+	if config.Member == "" {
+		hostname, _ := os.Hostname()
+		config.Member = fmt.Sprintf("%s-%d", hostname, os.Getpid())
+	}
+
+	ch, err := c.conn.Channel()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open coordination channel: %w", err)
+	}
+
+	return &Coordinator{
+		client:  c,
+		channel: ch,
+		config:  config,
+		members: map[string]time.Time{config.Member: time.Now()},
+		owned:   make(map[int]bool),
+	}, nil
+}
+
+// Run heartbeats and rebalances until ctx is done or Stop is called
+func (k *Coordinator) Run(ctx context.Context) error {
+	q, err := k.channel.QueueDeclare(
+		"",    // name: server-generated
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return fmt.Errorf("failed to declare coordination queue: %w", err)
+	}
+	err = k.channel.QueueBind(q.Name, k.config.Group, CoordinationExchange, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to bind coordination queue: %w", err)
+	}
+	msgs, err := k.channel.Consume(q.Name, "", true, true, false, false, nil)
+	if err != nil {
+		return fmt.Errorf("failed to consume coordination queue: %w", err)
+	}
+
+	log.Printf("🧭 %s joined %s (%d partitions)", k.config.Member, k.config.Group, k.config.Partitions)
+
+	ticker := time.NewTicker(k.config.Heartbeat)
+	defer ticker.Stop()
+
+	// Partitions are first assigned after one heartbeat, once the other
+	// members have been heard from
+	k.announce(false)
+	for {
+		select {
+		case <-ctx.Done():
+			k.Stop()
+			return nil
+		case d, ok := <-msgs:
+			if !ok {
+				return nil // stopped
+			}
+			var beat heartbeat
+			if err := json.Unmarshal(d.Body, &beat); err != nil || beat.Group != k.config.Group {
+				continue
+			}
+			if k.observe(beat) {
+				k.rebalance()
+			}
+		case <-ticker.C:
+			if k.Stopped() {
+				return nil
+			}
+			k.announce(false)
+			k.expire()
+			k.rebalance()
+		}
+	}
+}
+
+// Stop gives up all partitions and tells the other members to take them over
+func (k *Coordinator) Stop() {
+	k.busy.Lock()
+	defer k.busy.Unlock()
+
+	k.mu.Lock()
+	if k.stopped {
+		k.mu.Unlock()
+		return
+	}
+	k.stopped = true
+	owned := k.ownedPartitions()
+	k.owned = make(map[int]bool)
+	k.mu.Unlock()
+
+	for _, p := range owned {
+		k.config.Revoke(p)
+	}
+	k.announce(true)
+	k.channel.Close()
+	log.Printf("🧭 %s left %s", k.config.Member, k.config.Group)
+}
+
+// Stopped reports whether Stop was called
+func (k *Coordinator) Stopped() bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.stopped
+}
+
+// Owned is the partitions this replica consumes
+func (k *Coordinator) Owned() []int {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	return k.ownedPartitions()
+}
+
+// announce publishes a heartbeat
+func (k *Coordinator) announce(leaving bool) {
+	body, _ := json.Marshal(heartbeat{Group: k.config.Group, Member: k.config.Member, Leaving: leaving})
+	err := k.channel.Publish(CoordinationExchange, k.config.Group, false, false, amqp091.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+		Expiration:  fmt.Sprint(k.config.Heartbeat.Milliseconds()),
+	})
+	if err != nil {
+		log.Printf("⚠️  Failed to send %s heartbeat: %v", k.config.Group, err)
+	}
+}
+
+// observe records a heartbeat and reports whether membership changed
+func (k *Coordinator) observe(beat heartbeat) bool {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if beat.Member == k.config.Member {
+		return false
+	}
+	_, known := k.members[beat.Member]
+	if beat.Leaving {
+		delete(k.members, beat.Member)
+		return known
+	}
+	k.members[beat.Member] = time.Now()
+	return !known
+}
+
+// expire drops members that missed three heartbeats
+func (k *Coordinator) expire() {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	now := time.Now()
+	k.members[k.config.Member] = now
+	for member, seen := range k.members {
+		if now.Sub(seen) > 3*k.config.Heartbeat {
+			delete(k.members, member)
+			log.Printf("🧭 %s: lost member %s", k.config.Group, member)
+		}
+	}
+}
+
+// rebalance revokes partitions now owned by other members, then assigns the new ones
+func (k *Coordinator) rebalance() {
+	k.busy.Lock()
+	defer k.busy.Unlock()
+
+	k.mu.Lock()
+	if k.stopped {
+		k.mu.Unlock()
+		return
+	}
+	members := make([]string, 0, len(k.members))
+	for member := range k.members {
+		members = append(members, member)
+	}
+	var assign, revoke []int
+	for p := 0; p < k.config.Partitions; p++ {
+		mine := owner(k.config.Group, p, members) == k.config.Member
+		if mine && !k.owned[p] {
+			assign = append(assign, p)
+		}
+		if !mine && k.owned[p] {
+			revoke = append(revoke, p)
+		}
+	}
+	k.mu.Unlock()
+
+	if len(assign) == 0 && len(revoke) == 0 {
+		return
+	}
+
+	for _, p := range revoke {
+		k.config.Revoke(p)
+		k.mu.Lock()
+		delete(k.owned, p)
+		k.mu.Unlock()
+	}
+	for _, p := range assign {
+		if err := k.config.Assign(p); err != nil {
+			log.Printf("⚠️  Failed to take %s partition %d: %v", k.config.Group, p, err)
+			continue // retried on the next heartbeat
+		}
+		k.mu.Lock()
+		k.owned[p] = true
+		k.mu.Unlock()
+	}
+
+	log.Printf("🧭 %s: %d member(s), %s owns partitions %v", k.config.Group, len(members), k.config.Member, k.Owned())
+}
+
+// ownedPartitions lists owned partitions in order; callers hold k.mu
+func (k *Coordinator) ownedPartitions() []int {
+	owned := make([]int, 0, len(k.owned))
+	for p := range k.owned {
+		owned = append(owned, p)
+	}
+	slices.Sort(owned)
+	return owned
+}
+
+// owner picks a partition's member by rendezvous hashing
+func owner(group string, partition int, members []string) string {
+	var best string
+	var bestScore uint64
+	for _, member := range members {
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%s/%d/%s", group, partition, member)
+		if score := mix64(h.Sum64()); best == "" || score > bestScore || (score == bestScore && member < best) {
+			best, bestScore = member, score
+		}
+	}
+	return best
+}
+
+// mix64 spreads FNV's output over all bits (the splitmix64 finalizer); FNV
+// alone scores keys that differ in one character alike, so neighbouring
+// partitions went to the same member
+func mix64(x uint64) uint64 {
+	x ^= x >> 30
+	x *= 0xbf58476d1ce4e5b9
+	x ^= x >> 27
+	x *= 0x94d049bb133111eb
+	x ^= x >> 31
+	return x
+}
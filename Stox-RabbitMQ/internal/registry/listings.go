@@ -0,0 +1,104 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"sync"
+	"time"
+
+	"stox-rabbitmq/internal/storage"
+)
+
+// Listing is a product's listing on one marketplace
+type Listing struct {
+	ListingID string    `json:"listing_id"`
+	UserID    string    `json:"user_id,omitempty"` // the account it is listed with; empty for older confirmations
+	ListedAt  time.Time `json:"listed_at"`
+}
+
+// Listings records where products are listed, one blob per product keyed
+// by marketplace
+type Listings struct {
+	store storage.Store
+	mu    sync.Mutex // serializes read-modify-write of a product's listings
+}
+
+// NewListings creates a listing registry on the store
+func NewListings(store storage.Store) *Listings {
+	return &Listings{store: store}
+}
+
+func (l *Listings) key(productID string) string {
+	return "listings/" + url.PathEscape(productID) + ".json"
+}
+
+// Get returns a product's listings by marketplace
+func (l *Listings) Get(ctx context.Context, productID string) (map[string]Listing, error) {
+	listings := make(map[string]Listing)
+	data, err := l.store.Get(ctx, l.key(productID))
+	if errors.Is(err, storage.ErrNotFound) {
+		return listings, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to load listings of %s: %w", productID, err)
+	}
+	if err := json.Unmarshal(data, &listings); err != nil {
+		return nil, fmt.Errorf("failed to decode listings of %s: %w", productID, err)
+	}
+	return listings, nil
+}
+
+// Record saves a product's listing on a marketplace, replacing an earlier one
+func (l *Listings) Record(ctx context.Context, productID, marketplace string, listing Listing) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	listings, err := l.Get(ctx, productID)
+	if err != nil {
+		return err
+	}
+	listings[marketplace] = listing
+
+	data, err := json.Marshal(listings)
+	if err != nil {
+		return err
+	}
+	if err := l.store.Put(ctx, l.key(productID), data); err != nil {
+		return fmt.Errorf("failed to save listings of %s: %w", productID, err)
+	}
+	return nil
+}
+
+// Skipped is a marketplace a command addresses but is not sent to
+type Skipped struct {
+	Marketplace string
+	Reason      string
+}
+
+// Targets narrows resolved marketplaces to those the product is listed on
+// with an account its owner still has connected
+func (r *Registry) Targets(marketplaces []string, listings map[string]Listing) ([]string, []Skipped) {
+	var targets []string
+	var skipped []Skipped
+	for _, name := range marketplaces {
+		listing, ok := listings[name]
+		switch {
+		case !ok:
+			skipped = append(skipped, Skipped{name, "not listed"})
+		case listing.UserID != "" && !r.connected(listing.UserID, name):
+			skipped = append(skipped, Skipped{name, fmt.Sprintf("no account connected for %s", listing.UserID)})
+		default:
+			targets = append(targets, name)
+		}
+	}
+	return targets, skipped
+}
+
+// connected reports whether a user has an account on a marketplace
+func (r *Registry) connected(userID, marketplace string) bool {
+	_, ok := r.Account(userID, marketplace)
+	return ok
+}
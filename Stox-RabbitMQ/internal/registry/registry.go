@@ -0,0 +1,160 @@
+// Package registry knows which marketplaces sync commands may go to: the
+// marketplaces Stox integrates with, the seller accounts users connected to
+// them, and where each product is actually listed.
+//
+// Marketplaces and accounts come from a JSON file of the form
+//
+//	{"marketplaces": [{"name": "amazon", "enabled": true, "tags": ["global", "us"]}],
+//	 "accounts": [{"user_id": "user_123", "marketplace": "amazon", "seller_id": "A1..."}]}
+//
+// A default is embedded; Load reads a replacement. Listings are recorded in
+// the blob store as listing confirmations arrive.
+package registry
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+
+	"stox-rabbitmq/internal/models"
+)
+
+//go:embed data/marketplaces.json
+var defaultData embed.FS
+
+// AllMarketplaces targets every enabled marketplace
+const AllMarketplaces = "all"
+
+// Marketplace is a marketplace Stox can sync with
+type Marketplace struct {
+	Name    string   `json:"name"`
+	Enabled bool     `json:"enabled"`
+	Tags    []string `json:"tags,omitempty"` // regions and segments, e.g. tr, fashion
+}
+
+// Account is a user's seller account on a marketplace
+type Account struct {
+	UserID      string `json:"user_id"`
+	Marketplace string `json:"marketplace"`
+	SellerID    string `json:"seller_id"`
+}
+
+// Registry holds the marketplaces and the accounts connected to them
+type Registry struct {
+	marketplaces []Marketplace                 // in file order
+	accounts     map[string]map[string]Account // user -> marketplace -> account
+}
+
+// registryFile is the on-disk registry format
+type registryFile struct {
+	Marketplaces []Marketplace `json:"marketplaces"`
+	Accounts     []Account     `json:"accounts"`
+}
+
+// Load reads the registry from path, or the embedded default if path is ""
+func Load(path string) (*Registry, error) {
+	var r io.ReadCloser
+	var err error
+	if path == "" {
+		r, err = defaultData.Open("data/marketplaces.json")
+	} else {
+		r, err = os.Open(path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open marketplace registry: %w", err)
+	}
+	defer r.Close()
+	return Parse(r)
+}
+
+// Parse reads a registry and checks that accounts belong to known marketplaces
+func Parse(r io.Reader) (*Registry, error) {
+	var file registryFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse marketplace registry: %w", err)
+	}
+
+	reg := &Registry{accounts: make(map[string]map[string]Account)}
+	for _, m := range file.Marketplaces {
+		if m.Name == "" || m.Name == AllMarketplaces {
+			return nil, fmt.Errorf("marketplace registry: invalid marketplace name %q", m.Name)
+		}
+		if reg.Marketplace(m.Name) != nil {
+			return nil, fmt.Errorf("marketplace registry: duplicate marketplace %q", m.Name)
+		}
+		reg.marketplaces = append(reg.marketplaces, m)
+	}
+	for _, a := range file.Accounts {
+		if a.UserID == "" {
+			return nil, fmt.Errorf("marketplace registry: account on %s has no user_id", a.Marketplace)
+		}
+		if reg.Marketplace(a.Marketplace) == nil {
+			return nil, fmt.Errorf("marketplace registry: account of %s on unknown marketplace %q", a.UserID, a.Marketplace)
+		}
+		if reg.accounts[a.UserID] == nil {
+			reg.accounts[a.UserID] = make(map[string]Account)
+		}
+		reg.accounts[a.UserID][a.Marketplace] = a
+	}
+	return reg, nil
+}
+
+// Marketplace returns a marketplace by name, or nil if it is unknown
+func (r *Registry) Marketplace(name string) *Marketplace {
+	for i := range r.marketplaces {
+		if r.marketplaces[i].Name == name {
+			return &r.marketplaces[i]
+		}
+	}
+	return nil
+}
+
+// Enabled lists the names of the enabled marketplaces
+func (r *Registry) Enabled() []string {
+	var names []string
+	for _, m := range r.marketplaces {
+		if m.Enabled {
+			names = append(names, m.Name)
+		}
+	}
+	return names
+}
+
+// Account returns a user's account on a marketplace
+func (r *Registry) Account(userID, marketplace string) (Account, bool) {
+	a, ok := r.accounts[userID][marketplace]
+	return a, ok
+}
+
+// Resolve lists the enabled marketplaces a command addresses: every one for
+// "all", otherwise the one named, narrowed by the command's target
+func (r *Registry) Resolve(marketplace string, target *models.Target) []string {
+	candidates := []string{marketplace}
+	if marketplace == AllMarketplaces {
+		candidates = r.Enabled()
+	}
+
+	var resolved []string
+	for _, name := range candidates {
+		m := r.Marketplace(name)
+		if m == nil || !m.Enabled {
+			continue
+		}
+		if target != nil {
+			if len(target.Marketplaces) > 0 && !slices.Contains(target.Marketplaces, name) {
+				continue
+			}
+			if len(target.Tags) > 0 && !slices.ContainsFunc(target.Tags, func(tag string) bool { return slices.Contains(m.Tags, tag) }) {
+				continue
+			}
+			if slices.Contains(target.Exclude, name) {
+				continue
+			}
+		}
+		resolved = append(resolved, name)
+	}
+	return resolved
+}
@@ -0,0 +1,161 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Shape maps every JSON field path of a message to its wire kind,
+// e.g. "images[].width" -> "number"
+type Shape map[string]string
+
+// Snapshot is the stored contract of one published message version
+type Snapshot struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+	Fields  Shape  `json:"fields"`
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Describe derives the wire shape of a Go type from its json tags
+func Describe(t reflect.Type) Shape {
+	shape := make(Shape)
+	describe(t, "", shape)
+	return shape
+}
+
+func describe(t reflect.Type, path string, shape Shape) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		shape[path] = "string(date-time)"
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if path != "" {
+			shape[path] = "object"
+		}
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+
+			name := jsonName(field)
+			if name == "-" {
+				continue
+			}
+			if field.Anonymous && name == "" {
+				describe(field.Type, path, shape)
+				continue
+			}
+			if name == "" {
+				name = field.Name
+			}
+			describe(field.Type, joinPath(path, name), shape)
+		}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			shape[path] = "string" // base64 encoded bytes
+			return
+		}
+		shape[path] = "array"
+		describe(t.Elem(), path+"[]", shape)
+	case reflect.Map:
+		shape[path] = "object"
+		describe(t.Elem(), path+"{}", shape)
+	case reflect.String:
+		shape[path] = "string"
+	case reflect.Bool:
+		shape[path] = "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		shape[path] = "integer"
+	case reflect.Float32, reflect.Float64:
+		shape[path] = "number"
+	default:
+		shape[path] = "any"
+	}
+}
+
+// jsonName returns the name part of a field's json tag
+func jsonName(field reflect.StructField) string {
+	tag := field.Tag.Get("json")
+	if idx := strings.Index(tag, ","); idx >= 0 {
+		tag = tag[:idx]
+	}
+	return tag
+}
+
+func joinPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
+
+// Breaking lists the changes from old to current that existing consumers or
+// stored messages of the old version cannot survive. Added fields are fine.
+func Breaking(old, current Shape) []string {
+	var problems []string
+	for path, kind := range old {
+		now, ok := current[path]
+		switch {
+		case !ok:
+			problems = append(problems, fmt.Sprintf("field %q removed", path))
+		case kind == "integer" && now == "number":
+			// widening integer to number is compatible
+		case now != kind:
+			problems = append(problems, fmt.Sprintf("field %q changed from %s to %s", path, kind, now))
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// SnapshotPath returns the contract file for a message version
+func SnapshotPath(dir, name string, version int) string {
+	return filepath.Join(dir, fmt.Sprintf("%s.v%d.json", name, version))
+}
+
+// LoadSnapshot reads a stored contract
+func LoadSnapshot(path string) (Snapshot, error) {
+	var snapshot Snapshot
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return snapshot, err
+	}
+	if err := json.Unmarshal(data, &snapshot); err != nil {
+		return snapshot, fmt.Errorf("failed to parse contract %s: %w", path, err)
+	}
+	return snapshot, nil
+}
+
+// WriteSnapshot stores the current contract of a registered message type
+func WriteSnapshot(dir string, contract Contract) error {
+	snapshot := Snapshot{
+		Name:    contract.Name,
+		Version: contract.Version,
+		Fields:  Describe(contract.Type),
+	}
+
+	data, err := json.MarshalIndent(snapshot, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	return os.WriteFile(SnapshotPath(dir, contract.Name, contract.Version), append(data, '\n'), 0o644)
+}
@@ -0,0 +1,56 @@
+package schema
+
+import (
+	"errors"
+	"flag"
+	"io/fs"
+	"path/filepath"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "write contract snapshots for the current versions")
+
+const contractsDir = "contracts"
+
+// TestContractCompatibility fails when a struct change breaks a published
+// message version. Run `go test ./internal/schema -update` after bumping a
+// version to record the new contract.
+func TestContractCompatibility(t *testing.T) {
+	for _, contract := range Contracts() {
+		contract := contract
+		t.Run(contract.Name, func(t *testing.T) {
+			current := Describe(contract.Type)
+			path := SnapshotPath(contractsDir, contract.Name, contract.Version)
+
+			if *update {
+				if err := WriteSnapshot(contractsDir, contract); err != nil {
+					t.Fatalf("failed to write %s: %v", path, err)
+				}
+			}
+
+			snapshot, err := LoadSnapshot(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				t.Fatalf("no contract for %s v%d; run with -update to record it", contract.Name, contract.Version)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for _, problem := range Breaking(snapshot.Fields, current) {
+				t.Errorf("%s v%d: %s (bump the version and register an upcaster)", contract.Name, contract.Version, problem)
+			}
+
+			// Every older published version must still be readable
+			older, _ := filepath.Glob(filepath.Join(contractsDir, contract.Name+".v*.json"))
+			for _, file := range older {
+				old, err := LoadSnapshot(file)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if old.Version < contract.Version && !HasUpcaster(contract.Name, old.Version) {
+					t.Errorf("%s v%d is published but has no upcaster to v%d", contract.Name, old.Version, old.Version+1)
+				}
+			}
+		})
+	}
+}
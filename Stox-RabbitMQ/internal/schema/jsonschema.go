@@ -0,0 +1,189 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const jsonSchemaDraft = "https://json-schema.org/draft/2020-12/schema"
+
+// JSONSchema is the subset of JSON Schema generated for Stox messages
+type JSONSchema struct {
+	Schema               string                 `json:"$schema,omitempty"`
+	ID                   string                 `json:"$id,omitempty"`
+	Title                string                 `json:"title,omitempty"`
+	Type                 Types                  `json:"type,omitempty"`
+	Format               string                 `json:"format,omitempty"`
+	Properties           map[string]*JSONSchema `json:"properties,omitempty"`
+	Required             []string               `json:"required,omitempty"`
+	Items                *JSONSchema            `json:"items,omitempty"`
+	AdditionalProperties *JSONSchema            `json:"additionalProperties,omitempty"`
+	Minimum              *float64               `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64               `json:"exclusiveMinimum,omitempty"`
+	MinLength            *int                   `json:"minLength,omitempty"`
+	Enum                 []string               `json:"enum,omitempty"`
+}
+
+// Types is a JSON Schema "type", written as a string when it has one entry
+type Types []string
+
+// MarshalJSON writes a single type as a plain string
+func (t Types) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON accepts both the string and the array form
+func (t *Types) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = Types{single}
+		return nil
+	}
+	var many []string
+	if err := json.Unmarshal(data, &many); err != nil {
+		return err
+	}
+	*t = many
+	return nil
+}
+
+// Generate builds the JSON Schema of a registered contract.
+//
+// Constraints come from `jsonschema` struct tags:
+//
+//	ProductID string  `json:"product_id" jsonschema:"required"`
+//	Quantity  int     `json:"quantity" jsonschema:"minimum=1"`
+//	Price     float64 `json:"price" jsonschema:"exclusiveMinimum=0"`
+//	Type      string  `json:"type" jsonschema:"enum=stock|price|both"`
+func Generate(contract Contract) *JSONSchema {
+	s := generate(contract.Type)
+	s.Schema = jsonSchemaDraft
+	s.ID = fmt.Sprintf("https://stox.dev/schemas/%s.v%d.schema.json", contract.Name, contract.Version)
+	s.Title = fmt.Sprintf("%s v%d", contract.Name, contract.Version)
+	return s
+}
+
+func generate(t reflect.Type) *JSONSchema {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return &JSONSchema{Type: Types{"string"}, Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		s := &JSONSchema{Type: Types{"object"}, Properties: make(map[string]*JSONSchema)}
+		addFields(s, t)
+		return s
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &JSONSchema{Type: Types{"string", "null"}} // base64 encoded bytes
+		}
+		return &JSONSchema{Type: Types{"array", "null"}, Items: generate(t.Elem())}
+	case reflect.Map:
+		return &JSONSchema{Type: Types{"object", "null"}, AdditionalProperties: generate(t.Elem())}
+	case reflect.String:
+		return &JSONSchema{Type: Types{"string"}}
+	case reflect.Bool:
+		return &JSONSchema{Type: Types{"boolean"}}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &JSONSchema{Type: Types{"integer"}}
+	case reflect.Float32, reflect.Float64:
+		return &JSONSchema{Type: Types{"number"}}
+	}
+
+	return &JSONSchema{} // interface{}: anything goes
+}
+
+// addFields adds the exported fields of a struct to an object schema
+func addFields(s *JSONSchema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name := jsonName(field)
+		if name == "-" {
+			continue
+		}
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			for embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			addFields(s, embedded)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := generate(field.Type)
+		if applyTag(property, field.Tag.Get("jsonschema")) {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = property
+	}
+}
+
+// applyTag applies jsonschema tag options and reports whether the field is required
+func applyTag(s *JSONSchema, tag string) bool {
+	required := false
+	for _, option := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(option), "=")
+		switch key {
+		case "required":
+			required = true
+			if len(s.Type) == 1 && s.Type[0] == "string" {
+				one := 1
+				s.MinLength = &one
+			}
+		case "minimum":
+			if min, err := strconv.ParseFloat(value, 64); err == nil {
+				s.Minimum = &min
+			}
+		case "exclusiveMinimum":
+			if min, err := strconv.ParseFloat(value, 64); err == nil {
+				s.ExclusiveMinimum = &min
+			}
+		case "enum":
+			s.Enum = strings.Split(value, "|")
+		}
+	}
+	return required
+}
+
+// WriteJSONSchemas writes one schema file per registered contract into dir
+func WriteJSONSchemas(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create schema directory: %w", err)
+	}
+
+	var written []string
+	for _, contract := range Contracts() {
+		data, err := json.MarshalIndent(Generate(contract), "", "  ")
+		if err != nil {
+			return written, fmt.Errorf("failed to marshal %s schema: %w", contract.Name, err)
+		}
+
+		path := filepath.Join(dir, fmt.Sprintf("%s.v%d.schema.json", contract.Name, contract.Version))
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return written, fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		written = append(written, path)
+	}
+
+	return written, nil
+}
@@ -0,0 +1,28 @@
+package schema
+
+import "stox-rabbitmq/internal/models"
+
+// Current versions of the Stox message contracts.
+// Bump a version when a change is not backward compatible, keep the old
+// contract file under contracts/ and register an upcaster from it.
+const (
+	ProductVersion            = 1
+	OrderVersion              = 1
+	InventoryUpdateVersion    = 1
+	MarketplaceListingVersion = 1
+	ProcessingEventVersion    = 1
+	ListingReviewVersion      = 1
+	StockChangeVersion        = 1
+	PriceChangeVersion        = 1
+)
+
+func init() {
+	Register("product", ProductVersion, models.Product{})
+	Register("order", OrderVersion, models.Order{})
+	Register("inventory_update", InventoryUpdateVersion, models.InventoryUpdate{})
+	Register("marketplace_listing", MarketplaceListingVersion, models.MarketplaceListing{})
+	Register("processing_event", ProcessingEventVersion, models.ProcessingEvent{})
+	Register("listing_review", ListingReviewVersion, models.ListingReview{})
+	Register("stock_change", StockChangeVersion, models.StockChange{})
+	Register("price_change", PriceChangeVersion, models.PriceChange{})
+}
@@ -0,0 +1,176 @@
+// Package schema versions the message contracts exchanged between Stox services.
+//
+// Every published message type is registered with a name and its current
+// version. Publishers stamp both into the message headers; consumers upcast
+// older payloads to the current version before the handler sees them, so a
+// contract can change without stopping every service at once.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// Header names carried on every versioned message
+const (
+	HeaderName    = "x-schema"
+	HeaderVersion = "x-schema-version"
+)
+
+// Upcaster converts a decoded payload from one version to the next
+type Upcaster func(payload map[string]interface{}) (map[string]interface{}, error)
+
+// Contract describes one registered message type
+type Contract struct {
+	Name    string
+	Version int
+	Type    reflect.Type
+}
+
+var (
+	mu        sync.RWMutex
+	byName    = make(map[string]Contract)
+	byType    = make(map[reflect.Type]Contract)
+	upcasters = make(map[string]map[int]Upcaster) // name -> from version -> upcaster
+)
+
+// Register declares the current version of a message type.
+// sample is a value of the Go type that is marshalled on the wire.
+func Register(name string, version int, sample interface{}) {
+	t := reflect.TypeOf(sample)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	contract := Contract{Name: name, Version: version, Type: t}
+	byName[name] = contract
+	byType[t] = contract
+}
+
+// RegisterUpcaster adds a converter from version `from` to `from+1` of a message type
+func RegisterUpcaster(name string, from int, upcaster Upcaster) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	if upcasters[name] == nil {
+		upcasters[name] = make(map[int]Upcaster)
+	}
+	upcasters[name][from] = upcaster
+}
+
+// Lookup returns the contract registered for a message value
+func Lookup(message interface{}) (Contract, bool) {
+	t := reflect.TypeOf(message)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	mu.RLock()
+	defer mu.RUnlock()
+
+	contract, ok := byType[t]
+	return contract, ok
+}
+
+// Contracts returns all registered contracts sorted by name
+func Contracts() []Contract {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	contracts := make([]Contract, 0, len(byName))
+	for _, c := range byName {
+		contracts = append(contracts, c)
+	}
+	sort.Slice(contracts, func(i, j int) bool { return contracts[i].Name < contracts[j].Name })
+	return contracts
+}
+
+// HasUpcaster reports whether an upcaster from the given version is registered
+func HasUpcaster(name string, from int) bool {
+	mu.RLock()
+	defer mu.RUnlock()
+
+	_, ok := upcasters[name][from]
+	return ok
+}
+
+// Headers returns the schema headers for a message, or nil if it is not registered
+func Headers(message interface{}) map[string]interface{} {
+	contract, ok := Lookup(message)
+	if !ok {
+		return nil
+	}
+	return map[string]interface{}{
+		HeaderName:    contract.Name,
+		HeaderVersion: int32(contract.Version),
+	}
+}
+
+// Upcast brings a message body up to the current version of its contract.
+// Messages without schema headers, of unknown types or already current are
+// returned unchanged.
+func Upcast(headers map[string]interface{}, body []byte) ([]byte, error) {
+	name, _ := headers[HeaderName].(string)
+	version, ok := headerVersion(headers[HeaderVersion])
+	if name == "" || !ok {
+		return body, nil
+	}
+
+	mu.RLock()
+	contract, known := byName[name]
+	chain := upcasters[name]
+	mu.RUnlock()
+
+	if !known || version == contract.Version {
+		return body, nil
+	}
+	if version > contract.Version {
+		return nil, fmt.Errorf("%s v%d is newer than supported v%d", name, version, contract.Version)
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("failed to decode %s v%d: %w", name, version, err)
+	}
+
+	for v := version; v < contract.Version; v++ {
+		upcaster, ok := chain[v]
+		if !ok {
+			return nil, fmt.Errorf("no upcaster for %s v%d -> v%d", name, v, v+1)
+		}
+
+		var err error
+		if payload, err = upcaster(payload); err != nil {
+			return nil, fmt.Errorf("failed to upcast %s v%d -> v%d: %w", name, v, v+1, err)
+		}
+	}
+
+	return json.Marshal(payload)
+}
+
+// headerVersion reads the version header in any of the integer forms AMQP may deliver
+func headerVersion(value interface{}) (int, bool) {
+	switch v := value.(type) {
+	case int:
+		return v, true
+	case int8:
+		return int(v), true
+	case int16:
+		return int(v), true
+	case int32:
+		return int(v), true
+	case int64:
+		return int(v), true
+	case string:
+		n, err := strconv.Atoi(v)
+		return n, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,166 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+var (
+	generatedMu sync.Mutex
+	generated   = make(map[string]*JSONSchema)
+)
+
+// Validate checks a JSON body against the current schema of a registered contract.
+// It returns one entry per violation; an unknown contract name is an error.
+func Validate(name string, body []byte) ([]string, error) {
+	s, err := schemaFor(name)
+	if err != nil {
+		return nil, err
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(body, &value); err != nil {
+		return []string{fmt.Sprintf("invalid JSON: %v", err)}, nil
+	}
+
+	var violations []string
+	check(s, value, "$", &violations)
+	return violations, nil
+}
+
+// ValidateMessage checks a Go value before it is published
+func ValidateMessage(message interface{}) ([]string, error) {
+	contract, ok := Lookup(message)
+	if !ok {
+		return nil, nil
+	}
+
+	body, err := json.Marshal(message)
+	if err != nil {
+		return nil, err
+	}
+	return Validate(contract.Name, body)
+}
+
+// schemaFor returns the cached generated schema of a contract
+func schemaFor(name string) (*JSONSchema, error) {
+	generatedMu.Lock()
+	defer generatedMu.Unlock()
+
+	if s, ok := generated[name]; ok {
+		return s, nil
+	}
+
+	mu.RLock()
+	contract, ok := byName[name]
+	mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("no schema registered for %q", name)
+	}
+
+	s := Generate(contract)
+	generated[name] = s
+	return s, nil
+}
+
+// check validates value against s and appends violations found under path
+func check(s *JSONSchema, value interface{}, path string, violations *[]string) {
+	if len(s.Type) > 0 && !matchesType(s.Type, value) {
+		*violations = append(*violations, fmt.Sprintf("%s: expected %v, got %s", path, []string(s.Type), kindOf(value)))
+		return
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for _, name := range s.Required {
+			if _, ok := v[name]; !ok {
+				*violations = append(*violations, fmt.Sprintf("%s.%s: is required", path, name))
+			}
+		}
+
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if property, ok := s.Properties[k]; ok {
+				check(property, v[k], path+"."+k, violations)
+			} else if s.AdditionalProperties != nil {
+				check(s.AdditionalProperties, v[k], path+"."+k, violations)
+			}
+		}
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				check(s.Items, item, fmt.Sprintf("%s[%d]", path, i), violations)
+			}
+		}
+	case string:
+		if s.MinLength != nil && len(v) < *s.MinLength {
+			*violations = append(*violations, fmt.Sprintf("%s: must not be empty", path))
+		}
+		if len(s.Enum) > 0 && !contains(s.Enum, v) {
+			*violations = append(*violations, fmt.Sprintf("%s: %q is not one of %v", path, v, s.Enum))
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, v); err != nil {
+				*violations = append(*violations, fmt.Sprintf("%s: %q is not an RFC 3339 date-time", path, v))
+			}
+		}
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			*violations = append(*violations, fmt.Sprintf("%s: %v is below minimum %v", path, v, *s.Minimum))
+		}
+		if s.ExclusiveMinimum != nil && v <= *s.ExclusiveMinimum {
+			*violations = append(*violations, fmt.Sprintf("%s: %v must be greater than %v", path, v, *s.ExclusiveMinimum))
+		}
+	}
+}
+
+// matchesType reports whether a decoded JSON value has one of the allowed types
+func matchesType(types Types, value interface{}) bool {
+	kind := kindOf(value)
+	for _, t := range types {
+		if t == kind || (t == "number" && kind == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+// kindOf returns the JSON Schema type name of a decoded JSON value
+func kindOf(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return "unknown"
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,264 @@
+package seo
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"stox-rabbitmq/internal/models"
+)
+
+// Score component weights; they add up to 10
+const (
+	weightTitle       = 2.0
+	weightDescription = 1.0
+	weightKeywords    = 2.0
+	weightDensity     = 1.5
+	weightReadability = 1.5
+	weightUniqueness  = 1.0
+	weightCompliance  = 1.0
+)
+
+// Target ranges used by the scoring components
+const (
+	idealTitleMin       = 50  // characters
+	idealDescriptionMin = 150 // characters
+	idealKeywords       = 5
+	densityMin          = 0.01 // share of description terms per keyword
+	densityMax          = 0.04
+	easyReading         = 60.0 // readability at which full points are given
+	duplicateThreshold  = 0.5  // shingle overlap reported as duplicate content
+)
+
+// Keyword is an extracted term with its TF-IDF weight
+type Keyword struct {
+	Term   string
+	Weight float64
+}
+
+// Analyzer extracts keywords and scores listing content against a corpus
+type Analyzer struct {
+	corpus *Corpus
+}
+
+// NewAnalyzer creates an analyzer backed by a corpus
+func NewAnalyzer(corpus *Corpus) *Analyzer {
+	return &Analyzer{corpus: corpus}
+}
+
+// Corpus returns the analyzer's corpus
+func (a *Analyzer) Corpus() *Corpus {
+	return a.corpus
+}
+
+// ExtractKeywords returns up to n terms and two-word phrases from the title and
+// description ranked by TF-IDF. Title terms count twice.
+func (a *Analyzer) ExtractKeywords(locale, category, title, description string, n int) []Keyword {
+	titleTerms := Terms(locale, title)
+	descTerms := Terms(locale, description)
+
+	tf := make(map[string]float64)
+	for _, t := range titleTerms {
+		tf[t] += 2
+	}
+	for _, t := range descTerms {
+		tf[t]++
+	}
+	for _, b := range bigrams(titleTerms) {
+		tf[b] += 2
+	}
+	for _, b := range bigrams(descTerms) {
+		tf[b]++
+	}
+	for term, count := range tf { // phrases must repeat to beat their words
+		if strings.Contains(term, " ") && count < 2 {
+			delete(tf, term)
+		}
+	}
+
+	terms := make([]string, 0, len(tf))
+	for t := range tf {
+		terms = append(terms, t)
+	}
+	idf := a.corpus.idf(category, terms)
+
+	keywords := make([]Keyword, 0, len(terms))
+	for _, t := range terms {
+		keywords = append(keywords, Keyword{Term: t, Weight: tf[t] * idf[t]})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Weight != keywords[j].Weight {
+			return keywords[i].Weight > keywords[j].Weight
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+
+	// Drop words already covered by a higher ranked phrase
+	var out []Keyword
+	for _, k := range keywords {
+		covered := false
+		for _, o := range out {
+			if strings.Contains(o.Term, " ") && slices.Contains(strings.Fields(o.Term), k.Term) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			out = append(out, k)
+		}
+		if len(out) == n {
+			break
+		}
+	}
+	return out
+}
+
+// Analyze scores content and returns the total (0-10) and its itemized breakdown
+func (a *Analyzer) Analyze(c Content) (float64, []models.SEOScoreItem) {
+	limits := LimitsFor(c.Locale)
+	extracted := a.ExtractKeywords(c.Locale, c.Category, c.Title, c.Description, idealKeywords)
+
+	breakdown := []models.SEOScoreItem{
+		scoreTitle(c, limits),
+		scoreDescription(c, limits),
+		scoreKeywords(c, extracted),
+		scoreDensity(c, extracted),
+		scoreReadability(c),
+		a.scoreUniqueness(c),
+		scoreCompliance(c),
+	}
+
+	total := 0.0
+	for _, item := range breakdown {
+		total += item.Score
+	}
+	return round1(total), breakdown
+}
+
+func scoreTitle(c Content, limits Limits) models.SEOScoreItem {
+	item := models.SEOScoreItem{Name: "title_length", Max: weightTitle}
+	n := utf8.RuneCountInString(c.Title)
+	switch {
+	case n == 0:
+		item.Notes = append(item.Notes, "title is empty")
+	case n > limits.TitleMax:
+		item.Notes = append(item.Notes, fmt.Sprintf("title is %d characters, limit is %d", n, limits.TitleMax))
+	case n < idealTitleMin:
+		item.Score = weightTitle * float64(n) / idealTitleMin
+		item.Notes = append(item.Notes, fmt.Sprintf("title is %d characters, aim for at least %d", n, idealTitleMin))
+	default:
+		item.Score = weightTitle
+	}
+	item.Score = round1(item.Score)
+	return item
+}
+
+func scoreDescription(c Content, limits Limits) models.SEOScoreItem {
+	item := models.SEOScoreItem{Name: "description_length", Max: weightDescription}
+	n := utf8.RuneCountInString(c.Description)
+	switch {
+	case n > limits.DescriptionMax:
+		item.Notes = append(item.Notes, fmt.Sprintf("description is %d characters, limit is %d", n, limits.DescriptionMax))
+	case n < idealDescriptionMin:
+		item.Score = weightDescription * float64(n) / idealDescriptionMin
+		item.Notes = append(item.Notes, fmt.Sprintf("description is %d characters, aim for at least %d", n, idealDescriptionMin))
+	default:
+		item.Score = weightDescription
+	}
+	item.Score = round1(item.Score)
+	return item
+}
+
+// scoreKeywords rewards titles that contain the content's top keywords and a
+// reasonably sized keyword list
+func scoreKeywords(c Content, extracted []Keyword) models.SEOScoreItem {
+	item := models.SEOScoreItem{Name: "keywords", Max: weightKeywords}
+
+	title := strings.Join(Terms(c.Locale, c.Title), " ")
+	inTitle := 0
+	for _, k := range extracted {
+		if ContainsPhrase(title, k.Term) {
+			inTitle++
+		}
+	}
+	if inTitle < 2 {
+		item.Notes = append(item.Notes, fmt.Sprintf("only %d of the top keywords appear in the title", inTitle))
+	}
+	if len(c.Keywords) < idealKeywords {
+		item.Notes = append(item.Notes, fmt.Sprintf("%d keywords, aim for at least %d", len(c.Keywords), idealKeywords))
+	}
+
+	coverage := math.Min(float64(inTitle)/2, 1)
+	count := math.Min(float64(len(c.Keywords))/idealKeywords, 1)
+	item.Score = round1(weightKeywords * (0.6*coverage + 0.4*count))
+	return item
+}
+
+// scoreDensity checks that the top keywords appear in the description often
+// enough to rank but not so often that it reads as keyword stuffing
+func scoreDensity(c Content, extracted []Keyword) models.SEOScoreItem {
+	item := models.SEOScoreItem{Name: "keyword_density", Max: weightDensity}
+
+	terms := Terms(c.Locale, c.Description)
+	if len(terms) == 0 || len(extracted) == 0 {
+		item.Notes = append(item.Notes, "no keywords found in description")
+		return item
+	}
+	text := strings.Join(terms, " ")
+
+	checked := extracted[:min(3, len(extracted))]
+	total := 0.0
+	for _, k := range checked {
+		density := float64(strings.Count(" "+text+" ", " "+k.Term+" ")) / float64(len(terms))
+		switch {
+		case density < densityMin:
+			total += density / densityMin
+			item.Notes = append(item.Notes, fmt.Sprintf("%q density %.1f%% is low", k.Term, density*100))
+		case density > densityMax:
+			total += math.Max(0, 1-(density-densityMax)/densityMax)
+			item.Notes = append(item.Notes, fmt.Sprintf("%q density %.1f%% looks like keyword stuffing", k.Term, density*100))
+		default:
+			total++
+		}
+	}
+	item.Score = round1(weightDensity * total / float64(len(checked)))
+	return item
+}
+
+func scoreReadability(c Content) models.SEOScoreItem {
+	item := models.SEOScoreItem{Name: "readability", Max: weightReadability}
+	ease := Readability(c.Locale, c.Description)
+	if ease < easyReading {
+		item.Notes = append(item.Notes, fmt.Sprintf("reading ease %.0f/100, aim for %.0f or more", ease, easyReading))
+	}
+	item.Score = round1(weightReadability * math.Min(ease/easyReading, 1))
+	return item
+}
+
+func (a *Analyzer) scoreUniqueness(c Content) models.SEOScoreItem {
+	item := models.SEOScoreItem{Name: "uniqueness", Max: weightUniqueness}
+	similarity, match := a.corpus.mostSimilar(c.Category, c.Locale, c.Description)
+	if similarity >= duplicateThreshold {
+		item.Notes = append(item.Notes, fmt.Sprintf("description is %.0f%% similar to existing content: %q",
+			similarity*100, Truncate(match, 60)))
+	}
+	item.Score = round1(weightUniqueness * (1 - similarity))
+	return item
+}
+
+func scoreCompliance(c Content) models.SEOScoreItem {
+	item := models.SEOScoreItem{Name: "marketplace_rules", Max: weightCompliance}
+	violations, checks := Check(c)
+	for _, v := range violations {
+		item.Notes = append(item.Notes, v.String())
+	}
+	item.Score = round1(weightCompliance * float64(checks-len(violations)) / float64(checks))
+	return item
+}
+
+func round1(v float64) float64 {
+	return math.Round(v*10) / 10
+}
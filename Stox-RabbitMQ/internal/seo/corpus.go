@@ -0,0 +1,175 @@
+package seo
+
+import (
+	"bufio"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// maxLearnedDocuments bounds the documents Learn keeps per category
+const maxLearnedDocuments = 1000
+
+// Document is one product text in the corpus
+type Document struct {
+	Category string
+	Locale   string
+	Text     string
+}
+
+// Corpus is a set of product texts per category used for IDF weights and
+// duplicate detection
+type Corpus struct {
+	mu      sync.RWMutex
+	docs    map[string][]Document // category -> seeded documents
+	learned map[string][]Document // category -> documents added by Learn, oldest first
+}
+
+// NewCorpus creates a corpus seeded with the built-in category documents
+func NewCorpus() *Corpus {
+	c := &Corpus{docs: make(map[string][]Document), learned: make(map[string][]Document)}
+	for _, doc := range builtinCorpus {
+		c.Add(doc)
+	}
+	return c
+}
+
+// Add adds a document
+func (c *Corpus) Add(doc Document) {
+	category := strings.ToLower(doc.Category)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.docs[category] = append(c.docs[category], doc)
+}
+
+// Learn adds generated content so later duplicates are detected. Only the most
+// recent maxLearnedDocuments per category are kept.
+func (c *Corpus) Learn(doc Document) {
+	category := strings.ToLower(doc.Category)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	docs := append(c.learned[category], doc)
+	if len(docs) > maxLearnedDocuments {
+		docs = docs[1:]
+	}
+	c.learned[category] = docs
+}
+
+// LoadDir adds documents from <category>.txt files, one document per line.
+// Lines may start with a locale and a tab ("tr-TR\t..."); otherwise en-US is assumed.
+func (c *Corpus) LoadDir(dir string) (int, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return 0, err
+	}
+
+	loaded := 0
+	for _, path := range paths {
+		category := strings.TrimSuffix(filepath.Base(path), ".txt")
+
+		f, err := os.Open(path)
+		if err != nil {
+			return loaded, fmt.Errorf("failed to open corpus file: %w", err)
+		}
+
+		scanner := bufio.NewScanner(f)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			locale := DefaultLocale
+			if l, text, ok := strings.Cut(line, "\t"); ok {
+				locale, line = l, text
+			}
+			c.Add(Document{Category: category, Locale: locale, Text: line})
+			loaded++
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return loaded, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return loaded, nil
+}
+
+// documents returns a category's documents, or all documents for unknown categories
+func (c *Corpus) documents(category string) []Document {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	category = strings.ToLower(category)
+	if _, ok := c.docs[category]; ok {
+		return append(slices.Clip(c.docs[category]), c.learned[category]...)
+	}
+
+	var all []Document
+	for _, docs := range c.docs {
+		all = append(all, docs...)
+	}
+	for _, docs := range c.learned {
+		all = append(all, docs...)
+	}
+	return all
+}
+
+// idf returns smoothed inverse document frequencies for terms within a category.
+// Terms common in the category (e.g. "wireless" for electronics) weigh less.
+func (c *Corpus) idf(category string, terms []string) map[string]float64 {
+	docs := c.documents(category)
+
+	frequency := make(map[string]int, len(terms))
+	for _, doc := range docs {
+		present := make(map[string]bool)
+		for _, t := range Terms(doc.Locale, doc.Text) {
+			present[t] = true
+		}
+		for _, bigram := range bigrams(Terms(doc.Locale, doc.Text)) {
+			present[bigram] = true
+		}
+		for _, t := range terms {
+			if present[t] {
+				frequency[t]++
+			}
+		}
+	}
+
+	weights := make(map[string]float64, len(terms))
+	for _, t := range terms {
+		weights[t] = math.Log(float64(len(docs)+1)/float64(frequency[t]+1)) + 1
+	}
+	return weights
+}
+
+// mostSimilar returns the highest 3-word shingle overlap between text and any
+// document in the category, and that document's text
+func (c *Corpus) mostSimilar(category, locale, text string) (float64, string) {
+	target := shingles(locale, text, 3)
+
+	best, match := 0.0, ""
+	for _, doc := range c.documents(category) {
+		if doc.Text == text {
+			continue // the content itself, if already learned
+		}
+		if sim := jaccard(target, shingles(doc.Locale, doc.Text, 3)); sim > best {
+			best, match = sim, doc.Text
+		}
+	}
+	return best, match
+}
+
+func bigrams(terms []string) []string {
+	var out []string
+	for i := 0; i+1 < len(terms); i++ {
+		out = append(out, terms[i]+" "+terms[i+1])
+	}
+	return out
+}
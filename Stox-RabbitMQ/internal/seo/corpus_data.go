@@ -0,0 +1,62 @@
+package seo
+
+// builtinCorpus seeds IDF weights with typical marketplace copy per category.
+// Extend it at runtime with Corpus.LoadDir (SEO_CORPUS_DIR).
+var builtinCorpus = []Document{
+	// Electronics
+	{"electronics", LocaleEN, "Wireless Bluetooth speaker with deep bass, 20 hour battery life and waterproof design for outdoor use."},
+	{"electronics", LocaleEN, "Noise cancelling over-ear headphones with Bluetooth 5.3, USB-C fast charging and a built-in microphone for calls."},
+	{"electronics", LocaleEN, "4K Ultra HD smart TV with HDR, built-in streaming apps, voice control and three HDMI ports."},
+	{"electronics", LocaleEN, "Portable power bank with 20000mAh capacity, dual USB output and fast charging for phones and tablets."},
+	{"electronics", LocaleEN, "Wireless charging pad compatible with Qi phones, slim design with LED indicator and overheat protection."},
+	{"electronics", LocaleEN, "Mechanical gaming keyboard with RGB backlight, hot-swappable switches and a detachable USB-C cable."},
+	{"electronics", LocaleEN, "Full HD security camera with night vision, motion detection, two-way audio and cloud recording over WiFi."},
+	{"electronics", LocaleTR, "Kablosuz Bluetooth kulaklık, aktif gürültü engelleme, 30 saat pil ömrü ve hızlı şarj desteği."},
+	{"electronics", LocaleTR, "Taşınabilir Bluetooth hoparlör, suya dayanıklı tasarım, güçlü bas ve uzun pil ömrü."},
+	{"electronics", LocaleTR, "Akıllı televizyon, 4K Ultra HD görüntü kalitesi, dahili uygulamalar ve sesli komut desteği."},
+	{"electronics", LocaleTR, "Hızlı şarj destekli powerbank, 20000mAh kapasite, çift USB çıkışı ve kompakt tasarım."},
+	{"electronics", LocaleDE, "Kabellose Bluetooth Kopfhörer mit aktiver Geräuschunterdrückung, 30 Stunden Akkulaufzeit und Schnellladefunktion."},
+	{"electronics", LocaleDE, "Tragbarer Bluetooth Lautsprecher, wasserdicht, mit kräftigem Bass und langer Akkulaufzeit."},
+	{"electronics", LocaleDE, "Smart TV mit 4K Ultra HD, HDR, integrierten Streaming Apps und Sprachsteuerung."},
+
+	// Wearables
+	{"wearables", LocaleEN, "Fitness tracker with heart rate monitor, sleep tracking, step counter and seven day battery life."},
+	{"wearables", LocaleEN, "Smart watch with GPS, blood oxygen sensor, workout modes and smartphone notifications."},
+	{"wearables", LocaleEN, "Waterproof activity band with heart rate tracking, calorie counter and silent vibration alarm."},
+	{"wearables", LocaleEN, "Running watch with built-in GPS, training plans, heart rate zones and music storage."},
+	{"wearables", LocaleEN, "Kids smart watch with GPS tracking, SOS button, video calls and a durable silicone strap."},
+	{"wearables", LocaleTR, "Akıllı saat, nabız ölçer, uyku takibi, adım sayar ve bildirim desteği."},
+	{"wearables", LocaleTR, "Su geçirmez akıllı bileklik, kalori takibi, nabız ölçümü ve uzun pil ömrü."},
+	{"wearables", LocaleTR, "GPS özellikli koşu saati, antrenman modları ve kalp atış hızı takibi."},
+	{"wearables", LocaleDE, "Fitness Tracker mit Herzfrequenzmessung, Schlafanalyse, Schrittzähler und sieben Tagen Akkulaufzeit."},
+	{"wearables", LocaleDE, "Smartwatch mit GPS, Blutsauerstoffsensor, Trainingsmodi und Benachrichtigungen vom Smartphone."},
+
+	// Home
+	{"home", LocaleEN, "Stainless steel cookware set with non-stick coating, glass lids and stay-cool handles, dishwasher safe."},
+	{"home", LocaleEN, "Robot vacuum cleaner with smart mapping, app control, strong suction and automatic recharging."},
+	{"home", LocaleEN, "Memory foam pillow with breathable bamboo cover, ergonomic shape for neck support."},
+	{"home", LocaleEN, "LED desk lamp with adjustable brightness, color temperature modes and USB charging port."},
+	{"home", LocaleEN, "Cotton bed sheet set, 400 thread count, soft and breathable, fits mattresses up to 16 inches."},
+	{"home", LocaleTR, "Paslanmaz çelik tencere seti, yapışmaz kaplama, cam kapak ve bulaşık makinesinde yıkanabilir."},
+	{"home", LocaleTR, "Akıllı robot süpürge, haritalama özelliği, uygulama kontrolü ve otomatik şarj."},
+
+	// Fashion
+	{"fashion", LocaleEN, "Men's slim fit cotton shirt with button-down collar, breathable fabric for office and casual wear."},
+	{"fashion", LocaleEN, "Women's waterproof winter jacket with removable hood, warm lining and zip pockets."},
+	{"fashion", LocaleEN, "Genuine leather wallet with RFID blocking, multiple card slots and a coin pocket."},
+	{"fashion", LocaleEN, "Lightweight running shoes with breathable mesh upper, cushioned sole and non-slip grip."},
+	{"fashion", LocaleTR, "Erkek slim fit pamuklu gömlek, nefes alan kumaş, ofis ve günlük kullanım için ideal."},
+	{"fashion", LocaleTR, "Kadın su geçirmez mont, çıkarılabilir kapüşon, sıcak tutan astar ve fermuarlı cepler."},
+
+	// Beauty
+	{"beauty", LocaleEN, "Hydrating face serum with hyaluronic acid and vitamin C for brighter, smoother skin."},
+	{"beauty", LocaleEN, "Ionic hair dryer with three heat settings, cool shot button and concentrator nozzle."},
+	{"beauty", LocaleEN, "Long-lasting matte lipstick, cruelty free, enriched with vitamin E and jojoba oil."},
+	{"beauty", LocaleTR, "Hyaluronik asit ve C vitamini içeren nemlendirici yüz serumu, parlak ve pürüzsüz cilt."},
+
+	// Sports
+	{"sports", LocaleEN, "Non-slip yoga mat with carrying strap, extra thick cushioning for joints, eco-friendly material."},
+	{"sports", LocaleEN, "Adjustable dumbbell set with quick-change weights from 2 to 24 kg for home workouts."},
+	{"sports", LocaleEN, "Insulated stainless steel water bottle keeps drinks cold for 24 hours, leak-proof lid."},
+	{"sports", LocaleTR, "Kaymaz yoga matı, taşıma askılı, ekstra kalın ve çevre dostu malzeme."},
+}
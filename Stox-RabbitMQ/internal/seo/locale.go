@@ -0,0 +1,98 @@
+// Package seo holds marketplace SEO rules shared by the SEO and marketplace services.
+package seo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Supported content locales
+const (
+	LocaleTR = "tr-TR"
+	LocaleEN = "en-US"
+	LocaleDE = "de-DE"
+)
+
+// DefaultLocale is used for Product.SEO and for marketplaces without a locale
+const DefaultLocale = LocaleEN
+
+// Limits constrains generated content for a marketplace
+type Limits struct {
+	TitleMax       int // characters
+	DescriptionMax int // characters
+	KeywordsMax    int // count
+}
+
+// DefaultLimits apply to locales no marketplace is listed in
+var DefaultLimits = Limits{TitleMax: 150, DescriptionMax: 2000, KeywordsMax: 15}
+
+// Target is a marketplace and the locale its listings are written in
+type Target struct {
+	Marketplace string
+	Locale      string
+	Limits      Limits
+}
+
+// Targets lists where products are listed
+var Targets = []Target{
+	{Marketplace: "amazon", Locale: LocaleEN, Limits: Limits{TitleMax: 200, DescriptionMax: 2000, KeywordsMax: 15}},
+	{Marketplace: "trendyol", Locale: LocaleTR, Limits: Limits{TitleMax: 100, DescriptionMax: 3000, KeywordsMax: 10}},
+	{Marketplace: "hepsiburada", Locale: LocaleTR, Limits: Limits{TitleMax: 120, DescriptionMax: 5000, KeywordsMax: 10}},
+}
+
+// MarketplaceLocale returns the locale a marketplace's listings use
+func MarketplaceLocale(marketplace string) string {
+	for _, t := range Targets {
+		if t.Marketplace == marketplace {
+			return t.Locale
+		}
+	}
+	return DefaultLocale
+}
+
+// LimitsFor returns the tightest limits of all marketplaces using a locale,
+// so one piece of localized content fits every one of them
+func LimitsFor(locale string) Limits {
+	var limits Limits
+	found := false
+	for _, t := range Targets {
+		if t.Locale != locale {
+			continue
+		}
+		if !found {
+			limits, found = t.Limits, true
+			continue
+		}
+		limits.TitleMax = min(limits.TitleMax, t.Limits.TitleMax)
+		limits.DescriptionMax = min(limits.DescriptionMax, t.Limits.DescriptionMax)
+		limits.KeywordsMax = min(limits.KeywordsMax, t.Limits.KeywordsMax)
+	}
+	if !found {
+		return DefaultLimits
+	}
+	return limits
+}
+
+// Truncate shortens text to at most max characters, cutting at a word boundary
+func Truncate(text string, max int) string {
+	runes := []rune(text)
+	if max <= 0 || len(runes) <= max {
+		return text
+	}
+
+	cut := string(runes[:max])
+	if i := strings.LastIndexFunc(cut, unicode.IsSpace); i > 0 && !unicode.IsSpace(runes[max]) {
+		cut = cut[:i]
+	}
+	return strings.TrimRightFunc(cut, func(r rune) bool {
+		return unicode.IsSpace(r) || strings.ContainsRune("-|,;:", r)
+	})
+}
+
+// Lower lowercases text with the locale's casing rules (Turkish dotted/dotless i)
+func Lower(locale, text string) string {
+	if locale == LocaleTR {
+		return strings.ToLowerSpecial(unicode.TurkishCase, text)
+	}
+	return strings.ToLower(text)
+}
@@ -0,0 +1,160 @@
+package seo
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Content is generated listing copy to check
+type Content struct {
+	Locale      string
+	Category    string
+	Title       string
+	Description string
+	Keywords    []string
+}
+
+// Rule is a marketplace listing rule
+type Rule struct {
+	ID    string
+	Check func(t Target, c Content) error // nil when the content complies
+}
+
+// promotionalPhrases may not appear in titles on any marketplace
+var promotionalPhrases = map[string][]string{
+	LocaleEN: {"free shipping", "best price", "guaranteed", "sale", "discount", "cheap", "best seller"},
+	LocaleTR: {"ücretsiz kargo", "kargo bedava", "en iyi fiyat", "fiyat garantisi", "indirim", "ucuz", "çok satan"},
+	LocaleDE: {"kostenloser versand", "bestpreis", "rabatt", "billig", "angebot", "bestseller"},
+}
+
+// Rules apply to every target unless a rule's check skips the marketplace
+var Rules = []Rule{
+	{ID: "title_length", Check: func(t Target, c Content) error {
+		if n := utf8.RuneCountInString(c.Title); n > t.Limits.TitleMax {
+			return fmt.Errorf("title is %d characters, limit is %d", n, t.Limits.TitleMax)
+		}
+		return nil
+	}},
+	{ID: "description_length", Check: func(t Target, c Content) error {
+		if n := utf8.RuneCountInString(c.Description); n > t.Limits.DescriptionMax {
+			return fmt.Errorf("description is %d characters, limit is %d", n, t.Limits.DescriptionMax)
+		}
+		return nil
+	}},
+	{ID: "keyword_count", Check: func(t Target, c Content) error {
+		if len(c.Keywords) > t.Limits.KeywordsMax {
+			return fmt.Errorf("%d keywords, limit is %d", len(c.Keywords), t.Limits.KeywordsMax)
+		}
+		return nil
+	}},
+	{ID: "no_promotional_title", Check: func(t Target, c Content) error {
+		title := Lower(t.Locale, c.Title)
+		for _, phrase := range promotionalPhrases[t.Locale] {
+			if ContainsPhrase(title, phrase) {
+				return fmt.Errorf("title contains promotional phrase %q", phrase)
+			}
+		}
+		return nil
+	}},
+	{ID: "no_all_caps_words", Check: func(t Target, c Content) error {
+		for _, word := range strings.Fields(c.Title) {
+			if isShouting(word) {
+				return fmt.Errorf("title contains all-caps word %q", word)
+			}
+		}
+		return nil
+	}},
+	{ID: "no_special_characters", Check: func(t Target, c Content) error {
+		if t.Marketplace != "amazon" {
+			return nil
+		}
+		if i := strings.IndexAny(c.Title, "!$?{}^¬¦~*<>#"); i >= 0 {
+			r, _ := utf8.DecodeRuneInString(c.Title[i:])
+			return fmt.Errorf("title contains prohibited character %q", r)
+		}
+		return nil
+	}},
+}
+
+// TargetsFor returns the marketplaces listing in a locale, or a generic target
+// with DefaultLimits when none does
+func TargetsFor(locale string) []Target {
+	var targets []Target
+	for _, t := range Targets {
+		if t.Locale == locale {
+			targets = append(targets, t)
+		}
+	}
+	if len(targets) == 0 {
+		targets = append(targets, Target{Marketplace: "default", Locale: locale, Limits: DefaultLimits})
+	}
+	return targets
+}
+
+// Violation is a failed rule for one marketplace
+type Violation struct {
+	Marketplace string
+	Rule        string
+	Message     string
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s: %s", v.Marketplace, v.Message)
+}
+
+// Check runs all rules for every marketplace listing in the content's locale.
+// It returns the violations and the number of checks run.
+func Check(c Content) ([]Violation, int) {
+	var violations []Violation
+	checks := 0
+	for _, t := range TargetsFor(c.Locale) {
+		for _, rule := range Rules {
+			checks++
+			if err := rule.Check(t, c); err != nil {
+				violations = append(violations, Violation{Marketplace: t.Marketplace, Rule: rule.ID, Message: err.Error()})
+			}
+		}
+	}
+	return violations, checks
+}
+
+// ContainsPhrase reports whether text contains phrase on word boundaries
+func ContainsPhrase(text, phrase string) bool {
+	for i := 0; ; {
+		j := strings.Index(text[i:], phrase)
+		if j < 0 {
+			return false
+		}
+		start, end := i+j, i+j+len(phrase)
+		before, _ := utf8.DecodeLastRuneInString(text[:start])
+		after, _ := utf8.DecodeRuneInString(text[end:])
+		if (start == 0 || !isWordRune(before)) && (end == len(text) || !isWordRune(after)) {
+			return true
+		}
+		i = start + 1
+	}
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// isShouting reports words of five or more letters written entirely in capitals
+// (model numbers and acronyms like USB or HDMI are allowed)
+func isShouting(word string) bool {
+	letters := 0
+	for _, r := range word {
+		if unicode.IsDigit(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			if !unicode.IsUpper(r) {
+				return false
+			}
+			letters++
+		}
+	}
+	return letters > 4
+}
@@ -0,0 +1,148 @@
+package seo
+
+import (
+	"strings"
+	"unicode"
+)
+
+// stopwords are dropped before keyword extraction and density checks
+var stopwords = map[string]map[string]bool{
+	LocaleEN: wordSet("a an and are as at be by for from has have in is it its of on or that the this to with your you our we will can all any more most new now"),
+	LocaleTR: wordSet("ve ile bir bu şu da de için gibi çok daha en her ama veya ya ki mi mı mu mü olan olarak ise hem sizin bizim tüm yeni şimdi"),
+	LocaleDE: wordSet("der die das und mit für ein eine einer eines ist sind im in auf zu von den dem des oder als auch bei ihr ihre wir sie es nicht nur mehr neu jetzt alle"),
+}
+
+func wordSet(words string) map[string]bool {
+	set := make(map[string]bool)
+	for _, w := range strings.Fields(words) {
+		set[w] = true
+	}
+	return set
+}
+
+// Words splits text into lowercase words using the locale's casing rules
+func Words(locale, text string) []string {
+	return strings.FieldsFunc(Lower(locale, text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Terms returns the words of a text without stopwords and single characters
+func Terms(locale, text string) []string {
+	stop := stopwords[locale]
+	if stop == nil {
+		stop = stopwords[DefaultLocale]
+	}
+
+	var terms []string
+	for _, w := range Words(locale, text) {
+		if len([]rune(w)) > 1 && !stop[w] {
+			terms = append(terms, w)
+		}
+	}
+	return terms
+}
+
+// sentenceCount counts sentences ending in . ! or ? (at least one for non-empty text)
+func sentenceCount(text string) int {
+	count := 0
+	runes := []rune(strings.TrimSpace(text))
+	for i, r := range runes {
+		if r == '.' || r == '!' || r == '?' {
+			if i == len(runes)-1 || unicode.IsSpace(runes[i+1]) {
+				count++
+			}
+		}
+	}
+	if count == 0 && len(runes) > 0 {
+		count = 1
+	}
+	return count
+}
+
+// syllables estimates the syllables in a lowercase word.
+// Turkish has one syllable per vowel; English and German count vowel groups.
+func syllables(locale, word string) int {
+	vowels := "aeiouy"
+	switch locale {
+	case LocaleTR:
+		count := 0
+		for _, r := range word {
+			if strings.ContainsRune("aeıioöuü", r) {
+				count++
+			}
+		}
+		return max(count, 1)
+	case LocaleDE:
+		vowels = "aeiouyäöü"
+	}
+
+	count, inGroup := 0, false
+	for _, r := range word {
+		isVowel := strings.ContainsRune(vowels, r)
+		if isVowel && !inGroup {
+			count++
+		}
+		inGroup = isVowel
+	}
+	if locale == LocaleEN && count > 1 && strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		count-- // silent e
+	}
+	return max(count, 1)
+}
+
+// Readability returns a 0-100 reading ease score using the locale's formula:
+// Flesch (en-US), Ateşman (tr-TR) or Amstad (de-DE). Higher is easier.
+func Readability(locale, text string) float64 {
+	words := Words(locale, text)
+	if len(words) == 0 {
+		return 0
+	}
+
+	syllableCount := 0
+	for _, w := range words {
+		syllableCount += syllables(locale, w)
+	}
+
+	wordsPerSentence := float64(len(words)) / float64(sentenceCount(text))
+	syllablesPerWord := float64(syllableCount) / float64(len(words))
+
+	var score float64
+	switch locale {
+	case LocaleTR:
+		score = 198.825 - 40.175*syllablesPerWord - 2.610*wordsPerSentence
+	case LocaleDE:
+		score = 180 - wordsPerSentence - 58.5*syllablesPerWord
+	default:
+		score = 206.835 - 1.015*wordsPerSentence - 84.6*syllablesPerWord
+	}
+	return clamp(score, 0, 100)
+}
+
+// shingles returns the set of n-word sequences in a text
+func shingles(locale, text string, n int) map[string]bool {
+	words := Words(locale, text)
+	set := make(map[string]bool)
+	for i := 0; i+n <= len(words); i++ {
+		set[strings.Join(words[i:i+n], " ")] = true
+	}
+	return set
+}
+
+// jaccard returns the overlap of two sets, 0 (disjoint) to 1 (identical)
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	shared := 0
+	for k := range a {
+		if b[k] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return max(lo, min(hi, v))
+}
@@ -0,0 +1,49 @@
+// Package storage provides blob storage for Stox services.
+//
+// The Store interface follows S3 semantics (flat keys, whole-object
+// put/get, prefix listing) so the local filesystem backend used in
+// development can be swapped for S3 or an S3-compatible server.
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotFound is returned when a key does not exist
+var ErrNotFound = errors.New("object not found")
+
+// Object describes a stored blob
+type Object struct {
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// Store is an S3-like blob store
+type Store interface {
+	Put(ctx context.Context, key string, data []byte) error
+	Get(ctx context.Context, key string) ([]byte, error)
+	Delete(ctx context.Context, key string) error
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// Config selects and configures a storage backend
+type Config struct {
+	Backend string // filesystem (default) or s3
+	Dir     string // filesystem root
+	S3      S3Config
+}
+
+// New opens the configured backend
+func New(config Config) (Store, error) {
+	switch config.Backend {
+	case "", "filesystem":
+		return NewFileStore(config.Dir)
+	case "s3":
+		return NewS3Store(config.S3)
+	}
+	return nil, fmt.Errorf("unknown storage backend %q", config.Backend)
+}
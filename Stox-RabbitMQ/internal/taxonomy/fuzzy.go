@@ -0,0 +1,65 @@
+package taxonomy
+
+import (
+	"strings"
+	"unicode"
+)
+
+// normalize lowercases text and reduces it to letters and digits separated by
+// single spaces; Turkish dotted and dotless i fold to a plain i
+func normalize(text string) string {
+	var b strings.Builder
+	space := true
+	for _, r := range strings.ToLower(text) {
+		if unicode.Is(unicode.Mn, r) {
+			continue // combining dot left by lowercasing İ
+		}
+		if r == 'ı' {
+			r = 'i'
+		}
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			space = false
+		} else if !space {
+			b.WriteByte(' ')
+			space = true
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
+
+// bigrams returns the character bigrams of each word, padded so that word
+// starts and ends count
+func bigrams(text string) map[string]int {
+	grams := make(map[string]int)
+	for _, word := range strings.Fields(text) {
+		runes := []rune(" " + word + " ")
+		for i := 0; i+1 < len(runes); i++ {
+			grams[string(runes[i:i+2])]++
+		}
+	}
+	return grams
+}
+
+// similarity is the Dice coefficient of two texts' character bigrams, from
+// 0 (nothing shared) to 1 (same words); it tolerates plurals, typos and word order
+func similarity(a, b string) float64 {
+	a, b = normalize(a), normalize(b)
+	if a == "" || b == "" {
+		return 0
+	}
+	if a == b {
+		return 1
+	}
+
+	ga, gb := bigrams(a), bigrams(b)
+	shared, total := 0, 0
+	for g, n := range ga {
+		shared += min(n, gb[g])
+		total += n
+	}
+	for _, n := range gb {
+		total += n
+	}
+	return 2 * float64(shared) / float64(total)
+}
@@ -0,0 +1,287 @@
+package taxonomy
+
+import (
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+//go:embed data/*.json
+var defaultData embed.FS
+
+// Mapping sources
+const (
+	SourceRule       = "rule"       // mappings.json entry for the Stox category
+	SourceParentRule = "parent"     // mappings.json entry for an ancestor
+	SourceSuggestion = "suggestion" // fuzzy match above AutoMapScore
+)
+
+// AutoMapScore is the fuzzy score from which a suggestion is used without review
+const AutoMapScore = 0.8
+
+// resolveScore is the fuzzy score from which free text resolves to a Stox category
+const resolveScore = 0.75
+
+// maxSuggestions is how many suggestions an unmapped category carries
+const maxSuggestions = 3
+
+// minSuggestionScore drops candidates that share little more than a few letters
+const minSuggestionScore = 0.3
+
+// Suggestion is a fuzzy match candidate
+type Suggestion struct {
+	Category *Category
+	Score    float64 // 0-1
+}
+
+// Mapping is where a product category goes on one marketplace
+type Mapping struct {
+	Marketplace string
+	Input       string       // product category as given
+	Stox        *Category    // resolved Stox category, nil when unknown
+	Category    *Category    // marketplace category, nil when unmapped
+	Source      string       // SourceRule, SourceParentRule or SourceSuggestion
+	Attributes  []string     // required by the marketplace category and its ancestors
+	Suggestions []Suggestion // best candidates when unmapped
+}
+
+// Mapped reports whether a marketplace category was found
+func (m Mapping) Mapped() bool {
+	return m.Category != nil
+}
+
+// Mapper resolves product categories to marketplace categories
+type Mapper struct {
+	stox  *Tree
+	trees map[string]*Tree
+	rules map[string]map[string]string // marketplace -> Stox ID -> marketplace ID
+}
+
+// Load reads stox.json, <marketplace>.json and mappings.json. Files missing
+// from dir ("" for none) fall back to the embedded defaults.
+func Load(dir string) (*Mapper, error) {
+	open := func(name string) (io.ReadCloser, error) {
+		if dir != "" {
+			f, err := os.Open(filepath.Join(dir, name))
+			if err == nil {
+				log.Printf("🗂️  Loaded %s from %s", name, dir)
+				return f, nil
+			}
+			if !errors.Is(err, fs.ErrNotExist) {
+				return nil, err
+			}
+		}
+		return defaultData.Open("data/" + name)
+	}
+
+	names := map[string]bool{}
+	entries, _ := fs.Glob(defaultData, "data/*.json")
+	if dir != "" {
+		local, err := filepath.Glob(filepath.Join(dir, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, local...)
+	}
+	for _, e := range entries {
+		names[filepath.Base(e)] = true
+	}
+
+	m := &Mapper{trees: make(map[string]*Tree)}
+	for name := range names {
+		if name == "mappings.json" {
+			continue
+		}
+		f, err := open(name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open %s: %w", name, err)
+		}
+		tree, err := LoadTree(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", name, err)
+		}
+		if tree.Marketplace == Stox {
+			m.stox = tree
+		} else {
+			m.trees[tree.Marketplace] = tree
+		}
+	}
+	if m.stox == nil {
+		return nil, fmt.Errorf("no %s taxonomy found", Stox)
+	}
+
+	f, err := open("mappings.json")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open mappings.json: %w", err)
+	}
+	defer f.Close()
+	if err := json.NewDecoder(f).Decode(&m.rules); err != nil {
+		return nil, fmt.Errorf("failed to parse mappings.json: %w", err)
+	}
+
+	return m, m.checkRules()
+}
+
+// checkRules makes sure every rule points at categories that exist
+func (m *Mapper) checkRules() error {
+	for marketplace, rules := range m.rules {
+		tree, ok := m.trees[marketplace]
+		if !ok {
+			return fmt.Errorf("mappings.json: no category tree for %q", marketplace)
+		}
+		for stoxID, id := range rules {
+			if _, ok := m.stox.Get(stoxID); !ok {
+				return fmt.Errorf("mappings.json: unknown %s category %q", Stox, stoxID)
+			}
+			if _, ok := tree.Get(id); !ok {
+				return fmt.Errorf("mappings.json: unknown %s category %q", marketplace, id)
+			}
+		}
+	}
+	return nil
+}
+
+// Tree returns a marketplace's category tree (Stox for the Stox taxonomy)
+func (m *Mapper) Tree(marketplace string) (*Tree, bool) {
+	if marketplace == Stox {
+		return m.stox, true
+	}
+	tree, ok := m.trees[marketplace]
+	return tree, ok
+}
+
+// Resolve turns a free-text product category ("Electronics", "kulaklık",
+// "electronics/audio") into a Stox category by ID, name, alias or path,
+// then by fuzzy match
+func (m *Mapper) Resolve(text string) (*Category, bool) {
+	want := normalize(text)
+	if want == "" {
+		return nil, false
+	}
+
+	for _, c := range m.stox.Categories() {
+		if normalize(c.ID) == want || normalize(c.Path) == want {
+			return c, true
+		}
+		for _, name := range names(c) {
+			if normalize(name) == want {
+				return c, true
+			}
+		}
+	}
+
+	if best := suggest(m.stox, []string{text}, 1); len(best) > 0 && best[0].Score >= resolveScore {
+		return best[0].Category, true
+	}
+	return nil, false
+}
+
+// Map finds the marketplace category for a product category: a rule for the
+// Stox category, then a rule for its nearest mapped ancestor, then a fuzzy
+// suggestion scoring at least AutoMapScore. Unmapped results carry suggestions.
+func (m *Mapper) Map(marketplace, category string) Mapping {
+	mapping := Mapping{Marketplace: marketplace, Input: category}
+	tree, ok := m.trees[marketplace]
+	if !ok {
+		return mapping
+	}
+
+	queries := []string{category}
+	if stox, ok := m.Resolve(category); ok {
+		mapping.Stox = stox
+		queries = append(queries, names(stox)...)
+
+		rules := m.rules[marketplace]
+		if id, ok := rules[stox.ID]; ok {
+			return m.mapped(tree, mapping, id, SourceRule)
+		}
+		for _, parent := range m.stox.Ancestors(stox) {
+			if id, ok := rules[parent.ID]; ok {
+				return m.mapped(tree, mapping, id, SourceParentRule)
+			}
+		}
+	}
+
+	mapping.Suggestions = suggest(tree, queries, maxSuggestions)
+	if len(mapping.Suggestions) > 0 && mapping.Suggestions[0].Score >= AutoMapScore {
+		return m.mapped(tree, mapping, mapping.Suggestions[0].Category.ID, SourceSuggestion)
+	}
+	return mapping
+}
+
+// Suggest returns the marketplace categories that best match a product category
+func (m *Mapper) Suggest(marketplace, category string, n int) []Suggestion {
+	tree, ok := m.trees[marketplace]
+	if !ok {
+		return nil
+	}
+	queries := []string{category}
+	if stox, ok := m.Resolve(category); ok {
+		queries = append(queries, names(stox)...)
+	}
+	return suggest(tree, queries, n)
+}
+
+func (m *Mapper) mapped(tree *Tree, mapping Mapping, id, source string) Mapping {
+	c, _ := tree.Get(id)
+	mapping.Category = c
+	mapping.Source = source
+	mapping.Attributes = tree.RequiredAttributes(c)
+	return mapping
+}
+
+// suggest scores every category of a tree against the queries and returns the
+// best n; a category scores its best name or alias against any query
+func suggest(tree *Tree, queries []string, n int) []Suggestion {
+	var out []Suggestion
+	for _, c := range tree.Categories() {
+		best := 0.0
+		for _, q := range queries {
+			for _, name := range names(c) {
+				best = max(best, similarity(q, name))
+			}
+		}
+		if best >= minSuggestionScore {
+			out = append(out, Suggestion{Category: c, Score: best})
+		}
+	}
+
+	// Prefer the more specific category on ties
+	sort.SliceStable(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].Category.Depth > out[j].Category.Depth
+	})
+	if len(out) > n {
+		out = out[:n]
+	}
+	return out
+}
+
+func names(c *Category) []string {
+	return append([]string{c.Name}, c.Aliases...)
+}
+
+// String describes a suggestion as "Path (0.72)"
+func (s Suggestion) String() string {
+	return fmt.Sprintf("%s (%.2f)", s.Category.Path, s.Score)
+}
+
+// SuggestionList joins suggestions for log and review messages
+func SuggestionList(suggestions []Suggestion) string {
+	parts := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		parts[i] = s.String()
+	}
+	return strings.Join(parts, "; ")
+}
@@ -0,0 +1,132 @@
+// Package taxonomy maps Stox product categories to marketplace category trees.
+//
+// The Stox taxonomy and each marketplace's tree are JSON files of the form
+//
+//	{"marketplace": "amazon", "categories": [{"id": "172282", "name": "Electronics", "parent": "", "attributes": ["brand"]}]}
+//
+// mappings.json maps Stox category IDs to marketplace category IDs per
+// marketplace. Defaults are embedded; Load reads replacements from a directory.
+package taxonomy
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// Stox is the marketplace name of the Stox taxonomy tree
+const Stox = "stox"
+
+// Category is a node in a category tree
+type Category struct {
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Parent     string   `json:"parent,omitempty"`
+	Aliases    []string `json:"aliases,omitempty"`    // other names, e.g. translations
+	Attributes []string `json:"attributes,omitempty"` // required in addition to the parent's
+
+	Path  string `json:"-"` // names from the root, "Electronics > Audio > Headphones"
+	Depth int    `json:"-"` // 0 for roots
+}
+
+// Tree is one marketplace's category tree
+type Tree struct {
+	Marketplace string
+	categories  map[string]*Category
+	order       []string // IDs in file order
+}
+
+// treeFile is the on-disk tree format
+type treeFile struct {
+	Marketplace string     `json:"marketplace"`
+	Categories  []Category `json:"categories"`
+}
+
+// LoadTree reads a tree and checks that every parent exists and there are no cycles
+func LoadTree(r io.Reader) (*Tree, error) {
+	var file treeFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return nil, fmt.Errorf("failed to parse category tree: %w", err)
+	}
+	if file.Marketplace == "" {
+		return nil, fmt.Errorf("category tree has no marketplace")
+	}
+
+	tree := &Tree{Marketplace: file.Marketplace, categories: make(map[string]*Category, len(file.Categories))}
+	for i := range file.Categories {
+		c := &file.Categories[i]
+		if c.ID == "" || c.Name == "" {
+			return nil, fmt.Errorf("%s: category %d needs an id and a name", file.Marketplace, i)
+		}
+		if _, dup := tree.categories[c.ID]; dup {
+			return nil, fmt.Errorf("%s: duplicate category id %q", file.Marketplace, c.ID)
+		}
+		tree.categories[c.ID] = c
+		tree.order = append(tree.order, c.ID)
+	}
+
+	for _, id := range tree.order {
+		c := tree.categories[id]
+		names := []string{c.Name}
+		for p, seen := c.Parent, map[string]bool{id: true}; p != ""; {
+			parent, ok := tree.categories[p]
+			if !ok {
+				return nil, fmt.Errorf("%s: category %q has unknown parent %q", file.Marketplace, id, p)
+			}
+			if seen[p] {
+				return nil, fmt.Errorf("%s: category %q is its own ancestor", file.Marketplace, id)
+			}
+			seen[p] = true
+			names = append([]string{parent.Name}, names...)
+			p = parent.Parent
+		}
+		c.Path = strings.Join(names, " > ")
+		c.Depth = len(names) - 1
+	}
+	return tree, nil
+}
+
+// Get returns a category by ID
+func (t *Tree) Get(id string) (*Category, bool) {
+	c, ok := t.categories[id]
+	return c, ok
+}
+
+// Categories returns all categories in file order
+func (t *Tree) Categories() []*Category {
+	out := make([]*Category, 0, len(t.order))
+	for _, id := range t.order {
+		out = append(out, t.categories[id])
+	}
+	return out
+}
+
+// Ancestors returns a category's parents, nearest first
+func (t *Tree) Ancestors(c *Category) []*Category {
+	var out []*Category
+	for p := c.Parent; p != ""; {
+		parent := t.categories[p]
+		out = append(out, parent)
+		p = parent.Parent
+	}
+	return out
+}
+
+// RequiredAttributes returns the attributes a category and its ancestors require, sorted
+func (t *Tree) RequiredAttributes(c *Category) []string {
+	set := make(map[string]bool)
+	for _, n := range append([]*Category{c}, t.Ancestors(c)...) {
+		for _, a := range n.Attributes {
+			set[a] = true
+		}
+	}
+
+	attrs := make([]string, 0, len(set))
+	for a := range set {
+		attrs = append(attrs, a)
+	}
+	sort.Strings(attrs)
+	return attrs
+}
@@ -32,6 +32,7 @@ run_service() {
 echo "🏗️  Starting all microservices..."
 echo ""
 
+run_service "mock-marketplace"
 run_service "image-service"
 run_service "ai-service" 
 run_service "seo-service"